@@ -0,0 +1,107 @@
+// cmd/galymap-render/main.go
+
+// galymap-render replays a memory snapshot captured by the overlay (see the
+// snapshotFile setting) and draws what the overlay would show for it into a
+// PNG, without D2R, Windows or a GPU.
+package main
+
+import (
+	"errors"
+	"flag"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"strings"
+
+	"GalyMap/config"
+	"GalyMap/globals"
+	"GalyMap/mapdata"
+	"GalyMap/memory"
+	"GalyMap/pathfinding"
+	"GalyMap/scene"
+	"GalyMap/types"
+)
+
+func main() {
+	snapshotFile := flag.String("snapshot", "", "memory snapshot to replay")
+	outFile := flag.String("out", "overlay.png", "PNG file to write")
+	manifestFile := flag.String("sprites", "config/sprites.yaml", "sprite sheet manifest")
+	themeFile := flag.String("theme", "config/theme.yaml", "entity styles")
+	nipsFolderPath := flag.String("nips", config.NipsFolder, "NIP rules picking the items to show")
+	mapServerURL := flag.String("map-server", "", "map server to draw the level map from, none if empty")
+	mapCacheDir := flag.String("map-cache", "cache/maps", "map server cache directory")
+	pathTarget := flag.String("target", "exit", `route target: "exit", "waypoint", a super unique or quest object name, or "" for none`)
+	teleportRange := flag.Int("teleport", 0, "teleport range of the route, 0 to walk")
+	width := flag.Int("width", 1920, "width of the game client area in pixels")
+	height := flag.Int("height", 1080, "height of the game client area in pixels")
+	settingsFile := flag.String("settings", config.SettingsFile, "settings with the display mode and zoom")
+	displayMode := flag.String("mode", "", `display mode overriding the settings: "overlay", "minimap" or "hidden"`)
+	flag.Parse()
+	if *snapshotFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	globals.InitSettings()
+	if err := types.LoadNipRules(*nipsFolderPath); err != nil {
+		log.Printf("Failed to load NIP rules, no items will be shown: %v", err)
+	}
+
+	if err := memory.ReplaySnapshot(*snapshotFile, globals.Settings); err != nil {
+		log.Fatalf("Failed to replay %s: %v", *snapshotFile, err)
+	}
+	state := globals.CurrentGameState()
+
+	cfg, err := config.LoadConfig(*settingsFile)
+	if err != nil {
+		log.Fatalf("Failed to load %s: %v", *settingsFile, err)
+	}
+	if *displayMode != "" {
+		cfg.DisplayMode = *displayMode
+	}
+	display := scene.NewDisplay(cfg)
+	view := display.View(*width, *height)
+	assets, err := scene.LoadAssets(*manifestFile, *themeFile, view.UIScale)
+	if err != nil {
+		log.Fatalf("Failed to load sprites: %v", err)
+	}
+
+	items, _ := scene.DisplayedItems(state, nil, nil)
+	s := &scene.Scene{State: state, Items: items, Settings: globals.GetSettings()}
+	if *mapServerURL != "" && state.MapSeed != 0 {
+		client := mapdata.NewClient(*mapServerURL, *mapCacheDir)
+		level, err := client.Level(state.MapSeed, state.Difficulty, state.LevelNo)
+		if err != nil {
+			log.Printf("Failed to load level %d: %v", state.LevelNo, err)
+		} else {
+			s.Level = scene.NewLevel(level)
+		}
+	}
+
+	if s.Level != nil {
+		target := strings.ToLower(strings.TrimSpace(*pathTarget))
+		if marker, ok := scene.RouteTarget(state, scene.Markers(state, s.Level), target); ok {
+			start := image.Point{X: int(state.Pos.X), Y: int(state.Pos.Y)}
+			opts := pathfinding.Options{TeleportRange: *teleportRange}
+			s.Route, err = scene.PlanRoute(s.Level, start, image.Point{X: marker.X, Y: marker.Y}, opts)
+			if err != nil && !errors.Is(err, pathfinding.ErrNoPath) {
+				log.Printf("Route planning failed: %v", err)
+			}
+		}
+	}
+
+	if display.Mode == scene.DisplayHidden {
+		s = &scene.Scene{}
+	}
+	img := scene.Render(assets, view, s)
+	file, err := os.Create(*outFile)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", *outFile, err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		log.Fatalf("Failed to write %s: %v", *outFile, err)
+	}
+	log.Printf("Wrote %s", *outFile)
+}
@@ -3,9 +3,20 @@
 package config
 
 import (
-	"gopkg.in/yaml.v2"
+	"errors"
+	"io"
 	"log"
+	"maps"
 	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// SettingsFile is where the settings are loaded from and saved to
+	SettingsFile = "settings.yaml"
+	// NipsFolder holds the NIP rules of the item filter
+	NipsFolder = "./config/nips/"
 )
 
 // Settings defines the structure for configuration options
@@ -14,17 +25,77 @@ type Settings struct {
 	FpsCap          int    `yaml:"fpscap"`
 	GameWindowId    string `yaml:"gameWindowId"`
 	Debug           bool   `yaml:"debug"`
+	SnapshotFile    string `yaml:"snapshotFile"`
+	VerifyMapSeeds  bool   `yaml:"verifyMapSeeds"`
+	MapServerURL    string `yaml:"mapServerUrl"`
+	MapCacheDir     string `yaml:"mapCacheDir"`
+	PathTarget      string `yaml:"pathTarget"`
+	TeleportRange   int    `yaml:"teleportRange"`
+
+	DisplayMode string          `yaml:"displayMode"`
+	Zoom        float64         `yaml:"zoom"`
+	Minimap     MinimapSettings `yaml:"minimap"`
+
+	Hotkeys map[string]string `yaml:"hotkeys"`
+	// Layers are the layer settings switched by the hotkeys, by name. Layers missing from it are shown.
+	Layers map[string]bool `yaml:"layers,omitempty"`
+}
+
+// MinimapSettings place the corner minimap. Sizes are pixels at 1080p, scaled with the game resolution.
+type MinimapSettings struct {
+	Corner string  `yaml:"corner"`
+	Margin int     `yaml:"margin"`
+	Width  int     `yaml:"width"`
+	Height int     `yaml:"height"`
+	Zoom   float64 `yaml:"zoom"`
 }
 
 // defaultSettings provides default values for settings
 var defaultSettings = Settings{
-	PerformanceMode: 1,            // Default performance mode
-	FpsCap:          60,           // Default FPS cap
-	GameWindowId:    "D2R Window", // Example default window ID
-	Debug:           false,        // Debug mode off by default
+	PerformanceMode: 1,                       // Default performance mode
+	FpsCap:          60,                      // Default FPS cap
+	GameWindowId:    "D2R Window",            // Example default window ID
+	Debug:           false,                   // Debug mode off by default
+	SnapshotFile:    "",                      // No memory snapshot captured by default
+	VerifyMapSeeds:  false,                   // Check map seeds against rustdecrypt.dll, Windows only
+	MapServerURL:    "http://localhost:3002", // Local d2-mapserver
+	MapCacheDir:     "cache/maps",            // Level data fetched from the map server
+	PathTarget:      "exit",                  // Route to the next level exit; "waypoint", a super unique or quest object name, or "" for none
+	TeleportRange:   0,                       // Walk; set to about 25 to route in teleports
+
+	DisplayMode: "overlay", // Full-screen overlay; "minimap" for the corner minimap, "hidden" for neither
+	Zoom:        1,         // Overlay scale; 1 matches the in-game automap
+	Minimap: MinimapSettings{
+		Corner: "top-right", // top-left, top-right, bottom-left or bottom-right
+		Margin: 20,
+		Width:  360,
+		Height: 270,
+		Zoom:   0.5,
+	},
+
+	// Key combinations of the overlay actions, "" for none. The show and
+	// enable actions toggle the layer settings of the same name.
+	Hotkeys: map[string]string{
+		"cycleDisplayMode":   "Ctrl+Alt+M",
+		"zoomIn":             "Ctrl+Alt+PageUp",
+		"zoomOut":            "Ctrl+Alt+PageDown",
+		"reloadItemFilter":   "Ctrl+Alt+R",
+		"showNormalMobs":     "Ctrl+Alt+1",
+		"showUniqueMobs":     "Ctrl+Alt+2",
+		"showBosses":         "Ctrl+Alt+3",
+		"showDeadMobs":       "Ctrl+Alt+4",
+		"showPlayerMissiles": "Ctrl+Alt+5",
+		"showEnemyMissiles":  "Ctrl+Alt+6",
+		"showOtherPlayers":   "Ctrl+Alt+7",
+		"showShrines":        "Ctrl+Alt+8",
+		"showPortals":        "Ctrl+Alt+9",
+		"showChests":         "Ctrl+Alt+0",
+		"enableItemFilter":   "Ctrl+Alt+I",
+	},
 }
 
-// LoadConfig loads settings from a YAML file, creating the file with defaults if it doesn't exist
+// LoadConfig loads settings from a YAML file, creating the file with defaults
+// if it doesn't exist. Settings missing from the file keep their defaults.
 func LoadConfig(filePath string) (*Settings, error) {
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		err := createDefaultConfig(filePath)
@@ -40,22 +111,29 @@ func LoadConfig(filePath string) (*Settings, error) {
 	}
 	defer file.Close()
 
-	var config Settings
+	// Hotkeys in the file are merged into a copy of the default ones
+	config := defaultSettings
+	config.Hotkeys = maps.Clone(defaultSettings.Hotkeys)
 	decoder := yaml.NewDecoder(file)
 	err = decoder.Decode(&config)
-	if err != nil {
+	if err != nil && !errors.Is(err, io.EOF) {
 		return nil, err
 	}
 
 	return &config, nil
 }
 
-// createDefaultConfig creates a config file with default settings
-func createDefaultConfig(filePath string) error {
-	data, err := yaml.Marshal(&defaultSettings)
+// SaveConfig writes settings to a YAML file, for those changed at runtime.
+func SaveConfig(filePath string, settings *Settings) error {
+	data, err := yaml.Marshal(settings)
 	if err != nil {
 		return err
 	}
 
 	return os.WriteFile(filePath, data, 0644)
 }
+
+// createDefaultConfig creates a config file with default settings
+func createDefaultConfig(filePath string) error {
+	return SaveConfig(filePath, &defaultSettings)
+}
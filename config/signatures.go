@@ -0,0 +1,62 @@
+// config/signatures.go
+
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Signature describes how to locate one game offset in the D2R.exe module
+type Signature struct {
+	Name     string `yaml:"name"`
+	Pattern  string `yaml:"pattern"`
+	Count    int    `yaml:"count"`
+	Read     int    `yaml:"read"`
+	Relative bool   `yaml:"relative"`
+	Adjust   int64  `yaml:"adjust"`
+}
+
+// SignatureTable is the on-disk layout of signatures.yaml
+type SignatureTable struct {
+	Signatures []Signature `yaml:"signatures"`
+}
+
+// defaultSignatures is the signature table shipped with this build
+//
+//go:embed signatures.yaml
+var defaultSignatures []byte
+
+// LoadSignatures loads the signature table from a YAML file. If the file doesn't exist the built-in
+// table is used, and written to the file when create is set.
+// It also returns the raw file contents so callers can tell when the table changes.
+func LoadSignatures(filePath string, create bool) ([]Signature, []byte, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		data, err = defaultSignatures, nil
+		if create {
+			if err := os.WriteFile(filePath, defaultSignatures, 0644); err != nil {
+				return nil, nil, err
+			}
+			log.Printf("Created default signature table at %s\n", filePath)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var table SignatureTable
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %v", filePath, err)
+	}
+	for i, sig := range table.Signatures {
+		if sig.Name == "" || sig.Pattern == "" {
+			return nil, nil, fmt.Errorf("%s: signature %d needs a name and a pattern", filePath, i)
+		}
+	}
+	return table.Signatures, data, nil
+}
@@ -0,0 +1,33 @@
+// config/signatures_test.go
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSignaturesMissingFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "signatures.yaml")
+
+	signatures, table, err := LoadSignatures(filePath, false)
+	if err != nil {
+		t.Fatalf("LoadSignatures: %v", err)
+	}
+	if len(signatures) == 0 || !bytes.Equal(table, defaultSignatures) {
+		t.Errorf("missing file didn't fall back to the built-in table")
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Errorf("LoadSignatures without create wrote %s", filePath)
+	}
+
+	if _, _, err := LoadSignatures(filePath, true); err != nil {
+		t.Fatalf("LoadSignatures with create: %v", err)
+	}
+	written, err := os.ReadFile(filePath)
+	if err != nil || !bytes.Equal(written, defaultSignatures) {
+		t.Errorf("LoadSignatures with create didn't write the built-in table: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+// config/sprites.go
+
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SpriteRect is the area of the sprite sheet drawn for one entity kind
+type SpriteRect struct {
+	X    int `yaml:"x"`
+	Y    int `yaml:"y"`
+	W    int `yaml:"w"`
+	H    int `yaml:"h"`
+	Size int `yaml:"size"` // on-screen size in pixels, the manifest size if zero
+}
+
+// SpriteManifest is the on-disk layout of sprites.yaml
+type SpriteManifest struct {
+	Sheet   string                `yaml:"sheet"`
+	Size    int                   `yaml:"size"`
+	Sprites map[string]SpriteRect `yaml:"sprites"`
+}
+
+// defaultSpriteManifest is the manifest of the sprite sheet shipped with this build
+//
+//go:embed sprites.yaml
+var defaultSpriteManifest []byte
+
+// LoadSpriteManifest loads the sprite sheet manifest from a YAML file, creating the file with the built-in manifest if it doesn't exist.
+func LoadSpriteManifest(filePath string) (*SpriteManifest, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if err := os.WriteFile(filePath, defaultSpriteManifest, 0644); err != nil {
+			return nil, err
+		}
+		log.Printf("Created default sprite manifest at %s\n", filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest SpriteManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filePath, err)
+	}
+	if manifest.Size <= 0 {
+		return nil, fmt.Errorf("%s: size must be positive", filePath)
+	}
+	for kind, rect := range manifest.Sprites {
+		if rect.W <= 0 || rect.H <= 0 {
+			return nil, fmt.Errorf("%s: sprite %s needs a width and a height", filePath, kind)
+		}
+	}
+	return &manifest, nil
+}
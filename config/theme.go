@@ -0,0 +1,83 @@
+// config/theme.go
+
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"image/color"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ThemeStyle is how the overlay draws one entity kind
+type ThemeStyle struct {
+	Color string `yaml:"color"` // #rrggbb or #rrggbbaa
+	Shape string `yaml:"shape"` // dot, square, diamond, ring, cross, or sprite for the sprite sheet
+	Size  int    `yaml:"size"`  // on-screen size in pixels, the theme size if zero
+}
+
+// Theme is the on-disk layout of theme.yaml
+type Theme struct {
+	Size   int                   `yaml:"size"`
+	Styles map[string]ThemeStyle `yaml:"styles"`
+}
+
+// defaultTheme is the theme shipped with this build
+//
+//go:embed theme.yaml
+var defaultTheme []byte
+
+// LoadTheme loads the entity styles from a YAML file, creating the file with the built-in theme if it doesn't exist.
+func LoadTheme(filePath string) (*Theme, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if err := os.WriteFile(filePath, defaultTheme, 0644); err != nil {
+			return nil, err
+		}
+		log.Printf("Created default theme at %s\n", filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var theme Theme
+	if err := yaml.Unmarshal(data, &theme); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filePath, err)
+	}
+	if theme.Size <= 0 {
+		return nil, fmt.Errorf("%s: size must be positive", filePath)
+	}
+	for kind, style := range theme.Styles {
+		if style.Color != "" {
+			if _, err := ParseColor(style.Color); err != nil {
+				return nil, fmt.Errorf("%s: style %s: %v", filePath, kind, err)
+			}
+		}
+		if style.Size < 0 {
+			return nil, fmt.Errorf("%s: style %s: size must not be negative", filePath, kind)
+		}
+	}
+	return &theme, nil
+}
+
+// ParseColor parses a #rrggbb or #rrggbbaa color.
+func ParseColor(s string) (color.NRGBA, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != 6 && len(hex) != 8) {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q, want #rrggbb or #rrggbbaa", s)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q, want #rrggbb or #rrggbbaa", s)
+	}
+	return color.NRGBA{R: uint8(value >> 24), G: uint8(value >> 16), B: uint8(value >> 8), A: uint8(value)}, nil
+}
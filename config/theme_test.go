@@ -0,0 +1,103 @@
+// config/theme_test.go
+
+package config
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    color.NRGBA
+		wantErr bool
+	}{
+		{"#ff8000", color.NRGBA{R: 0xFF, G: 0x80, B: 0x00, A: 0xFF}, false},
+		{"#6E6E6EA0", color.NRGBA{R: 0x6E, G: 0x6E, B: 0x6E, A: 0xA0}, false},
+		{"#00000000", color.NRGBA{}, false},
+		{"", color.NRGBA{}, true},
+		{"ff8000", color.NRGBA{}, true},
+		{"#ff80", color.NRGBA{}, true},
+		{"#ff80001", color.NRGBA{}, true},
+		{"#ff8000ff00", color.NRGBA{}, true},
+		{"#gg8000", color.NRGBA{}, true},
+		{"#+f8000", color.NRGBA{}, true},
+		{"red", color.NRGBA{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    *Theme
+		wantErr string // part of the error, "" for none
+	}{
+		{
+			name: "styles",
+			yaml: "size: 12\nstyles:\n  boss:\n    color: \"#ff8000\"\n    shape: cross\n    size: 20\n  dragon:\n    shape: dot\n",
+			want: &Theme{Size: 12, Styles: map[string]ThemeStyle{
+				"boss":   {Color: "#ff8000", Shape: "cross", Size: 20},
+				"dragon": {Shape: "dot"},
+			}},
+		},
+		{name: "no styles", yaml: "size: 12\n", want: &Theme{Size: 12}},
+		{name: "malformed color", yaml: "size: 12\nstyles:\n  boss:\n    color: \"#ff80\"\n", wantErr: "style boss: invalid color"},
+		{name: "color name", yaml: "size: 12\nstyles:\n  boss:\n    color: orange\n", wantErr: "style boss: invalid color"},
+		{name: "negative size", yaml: "size: 12\nstyles:\n  boss:\n    size: -1\n", wantErr: "style boss: size must not be negative"},
+		{name: "no theme size", yaml: "styles: {}\n", wantErr: "size must be positive"},
+		{name: "not yaml", yaml: "size: [12\n", wantErr: "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "theme.yaml")
+			if err := os.WriteFile(filePath, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			theme, err := LoadTheme(filePath)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadTheme error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTheme: %v", err)
+			}
+			if theme.Size != tt.want.Size || len(theme.Styles) != len(tt.want.Styles) {
+				t.Fatalf("LoadTheme = %+v, want %+v", theme, tt.want)
+			}
+			for kind, style := range tt.want.Styles {
+				if theme.Styles[kind] != style {
+					t.Errorf("style %s = %+v, want %+v", kind, theme.Styles[kind], style)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadThemeMissingFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "theme.yaml")
+	theme, err := LoadTheme(filePath)
+	if err != nil {
+		t.Fatalf("LoadTheme: %v", err)
+	}
+	written, err := os.ReadFile(filePath)
+	if err != nil || !bytes.Equal(written, defaultTheme) {
+		t.Errorf("LoadTheme didn't write the built-in theme: %v", err)
+	}
+	if theme.Size <= 0 || len(theme.Styles) == 0 {
+		t.Errorf("built-in theme = %+v, want a size and styles", theme)
+	}
+}
@@ -0,0 +1,59 @@
+// globals/gamestate.go
+package globals
+
+import (
+	"GalyMap/types"
+	"sync/atomic"
+)
+
+// GameState is everything read from game memory during a single tick.
+// A new value is published every tick and must be treated as read-only
+// by consumers, including the slices it holds.
+type GameState struct {
+	Version        uint64
+	PlayerPointer  uintptr
+	UnitId         uint32
+	PlayerName     string
+	PlayerLevel    uint32
+	Experience     uint32
+	Pos            UnitPosition
+	LevelNo        uint32
+	Difficulty     uint16
+	MapSeed        uint32
+	MenuShown      bool
+	Mobs           []Mob
+	HoveredMob     Mob
+	PlayerMissiles []Missile
+	EnemyMissiles  []Missile
+	OtherPlayers   []Player
+	PartyList      []Player
+	Items          []types.Item
+	Objects        []Object
+}
+
+var (
+	currentGameState atomic.Pointer[GameState]
+	gameStateVersion atomic.Uint64
+	emptyGameState   = &GameState{}
+)
+
+// PublishGameState stamps state with the next version and makes it the current state.
+func PublishGameState(state *GameState) {
+	state.Version = gameStateVersion.Add(1)
+	currentGameState.Store(state)
+}
+
+// CurrentGameState returns the most recently published state. Before the
+// first tick it returns an empty state with Version 0.
+func CurrentGameState() *GameState {
+	if state := currentGameState.Load(); state != nil {
+		return state
+	}
+	return emptyGameState
+}
+
+// ResetGameState drops the current state so that CurrentGameState returns the
+// empty state again, as it does before the first tick.
+func ResetGameState() {
+	currentGameState.Store(nil)
+}
@@ -20,24 +20,17 @@ const (
 
 var (
 	Ticktock       int64
-	Items          []types.Item
-	Mobs           []Mob
-	HoveredMob     Mob
-	GameObjects    []Object
-	OtherPlayers   []Player
-	PartyList      []Player
 	MapSeed        uint32
 	ItemAlertList  map[string]bool
-	GameMemoryData map[string]interface{}
 	Settings       map[string]bool
 	FilteredItems  []types.ItemFootprint
 	DisplayedItems []types.ItemFootprint
 
 	// Mutexes for synchronizing access to shared data
-	GameDataMutex       sync.RWMutex
 	OffsetsMutex        sync.RWMutex
 	FilteredItemsMutex  sync.RWMutex
 	DisplayedItemsMutex sync.RWMutex
+	SettingsMutex       sync.RWMutex
 
 	// Offsets for reading memory
 	Offsets struct {
@@ -69,8 +62,7 @@ func InitSettings() {
 		"showChests":         true,
 	}
 
-	// Initialize GameMemoryData and ItemAlertList maps
-	GameMemoryData = make(map[string]interface{})
+	// Initialize ItemAlertList map
 	ItemAlertList = make(map[string]bool)
 	FilteredItems = make([]types.ItemFootprint, 0)
 
@@ -86,29 +78,6 @@ func IncrementTicktock() {
 
 // Safe getters and setters for shared data
 
-// GetGameMemoryData safely retrieves a copy of GameMemoryData
-func GetGameMemoryData() map[string]interface{} {
-	GameDataMutex.RLock()
-	defer GameDataMutex.RUnlock()
-
-	// Create a copy to prevent race conditions
-	dataCopy := make(map[string]interface{})
-	for k, v := range GameMemoryData {
-		dataCopy[k] = v
-	}
-	return dataCopy
-}
-
-// SetGameMemoryData safely updates GameMemoryData
-func SetGameMemoryData(data map[string]interface{}) {
-	GameDataMutex.RLock()
-	defer GameDataMutex.RUnlock()
-
-	for k, v := range data {
-		GameMemoryData[k] = v
-	}
-}
-
 // GetFilteredItems safely retrieves a copy of FilteredItems
 func GetFilteredItems() []types.ItemFootprint {
 	FilteredItemsMutex.RLock()
@@ -122,8 +91,8 @@ func GetFilteredItems() []types.ItemFootprint {
 
 // SetFilteredItems safely updates FilteredItems
 func SetFilteredItems(items []types.ItemFootprint) {
-	FilteredItemsMutex.RLock()
-	defer FilteredItemsMutex.RUnlock()
+	FilteredItemsMutex.Lock()
+	defer FilteredItemsMutex.Unlock()
 	FilteredItems = items
 }
 
@@ -140,11 +109,49 @@ func GetDisplayedItems() []types.ItemFootprint {
 
 // SetDisplayedItems safely updates DisplayedItems
 func SetDisplayedItems(items []types.ItemFootprint) {
-	DisplayedItemsMutex.RLock()
-	defer DisplayedItemsMutex.RUnlock()
+	DisplayedItemsMutex.Lock()
+	defer DisplayedItemsMutex.Unlock()
 	DisplayedItems = items
 }
 
+// GetSettings safely retrieves a copy of Settings
+func GetSettings() map[string]bool {
+	SettingsMutex.RLock()
+	defer SettingsMutex.RUnlock()
+
+	settingsCopy := make(map[string]bool, len(Settings))
+	for key, value := range Settings {
+		settingsCopy[key] = value
+	}
+	return settingsCopy
+}
+
+// SetSetting safely sets a setting. It reports false for settings that don't exist.
+func SetSetting(key string, value bool) bool {
+	SettingsMutex.Lock()
+	defer SettingsMutex.Unlock()
+
+	if _, exists := Settings[key]; !exists {
+		return false
+	}
+	Settings[key] = value
+	return true
+}
+
+// ToggleSetting safely flips a setting and returns its new value. It reports
+// false for settings that don't exist.
+func ToggleSetting(key string) (bool, bool) {
+	SettingsMutex.Lock()
+	defer SettingsMutex.Unlock()
+
+	value, exists := Settings[key]
+	if !exists {
+		return false, false
+	}
+	Settings[key] = !value
+	return !value, true
+}
+
 // GetOffset safely retrieves an offset value by key
 func GetOffset(key string) (uintptr, bool) {
 	OffsetsMutex.RLock()
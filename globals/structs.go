@@ -34,6 +34,7 @@ type Mob struct {
 	MonsterFlag    uint8
 	IsPlayerMinion bool
 	TextTitle      string
+	SuperUnique    string // name of a super unique monster, see memory.GetSuperUniqueName
 	Immunities     Immunities
 	HP             uint32
 	MaxHP          uint32
@@ -44,6 +45,14 @@ type Mob struct {
 	IsCorpse       bool
 }
 
+// Missile represents a projectile or spell effect in the game.
+type Missile struct {
+	TxtFileNo uint32
+	Mode      uint32
+	Pos       UnitPosition
+	Category  string
+}
+
 // Immunities represents the various immunities a Mob can have.
 type Immunities struct {
 	Physical uint32
@@ -77,12 +86,14 @@ type ProcessInfo struct {
 	PID     uint32
 	ExeName string
 	Title   string
-	Windows []WindowInfo // Added field to store window information
+	Windows []WindowInfo // the D2R window the overlay follows, kept up to date as it moves
 }
 
-// WindowInfo holds the position and size of a window
+// WindowInfo holds the position and size of the client area of a window, in
+// physical pixels of the virtual screen, and the DPI of the monitor it is on
 type WindowInfo struct {
 	Handle uintptr
+	PID    uint32
 	Title  string
 	Left   int32
 	Top    int32
@@ -90,4 +101,5 @@ type WindowInfo struct {
 	Bottom int32
 	Width  int32
 	Height int32
+	DPI    uint32
 }
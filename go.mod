@@ -7,8 +7,12 @@ require (
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20240506104042-037f3cc74f2a
 	github.com/hectorgimenez/d2go v0.0.0-20241107125602-b2c710dc5c09
 	github.com/lxn/win v0.0.0-20210218163916-a377121e959e
+	golang.org/x/image v0.25.0
 	golang.org/x/sys v0.27.0
 	gopkg.in/yaml.v2 v2.4.0
 )
 
-require github.com/expr-lang/expr v1.16.9 // indirect
+require (
+	github.com/expr-lang/expr v1.16.9 // indirect
+	golang.org/x/text v0.23.0 // indirect
+)
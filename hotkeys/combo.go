@@ -0,0 +1,93 @@
+// hotkeys/combo.go
+
+// Package hotkeys parses key combinations such as "Ctrl+Alt+M", dispatches
+// them to actions and, on Windows, listens for them as global hotkeys.
+package hotkeys
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modifier is a set of modifier keys, with the bits of the RegisterHotKey modifiers
+type Modifier uint32
+
+const (
+	Alt   Modifier = 0x0001
+	Ctrl  Modifier = 0x0002
+	Shift Modifier = 0x0004
+	Win   Modifier = 0x0008
+)
+
+// modifierNames are the accepted names of the modifiers, lower case
+var modifierNames = map[string]Modifier{
+	"alt":     Alt,
+	"ctrl":    Ctrl,
+	"control": Ctrl,
+	"shift":   Shift,
+	"win":     Win,
+	"windows": Win,
+}
+
+// Combo is a key pressed with a set of modifiers
+type Combo struct {
+	Modifiers Modifier
+	Key       Key
+}
+
+// ParseCombo parses modifiers and a key joined with "+", such as
+// "Ctrl+Shift+F5", ignoring case and spaces. The plus key is "Plus", or a
+// trailing "+" as in "Ctrl++".
+func ParseCombo(text string) (Combo, error) {
+	var combo Combo
+	trimmed := strings.ReplaceAll(strings.TrimSpace(text), " ", "")
+	if trimmed == "" {
+		return Combo{}, fmt.Errorf("empty key combination")
+	}
+	parts := strings.Split(trimmed, "+")
+	if strings.HasSuffix(trimmed, "++") || trimmed == "+" {
+		parts = append(parts[:len(parts)-2], "plus")
+	}
+
+	keySet := false
+	for _, part := range parts {
+		name := strings.ToLower(part)
+		if name == "" {
+			return Combo{}, fmt.Errorf("%q: missing key between '+'", text)
+		}
+		if modifier, ok := modifierNames[name]; ok {
+			if combo.Modifiers&modifier != 0 {
+				return Combo{}, fmt.Errorf("%q: %s is given twice", text, part)
+			}
+			combo.Modifiers |= modifier
+			continue
+		}
+		key, ok := keyNames[name]
+		if !ok {
+			return Combo{}, fmt.Errorf("%q: unknown key %s", text, part)
+		}
+		if keySet {
+			return Combo{}, fmt.Errorf("%q: only one key besides the modifiers is allowed", text)
+		}
+		combo.Key = key
+		keySet = true
+	}
+	if !keySet {
+		return Combo{}, fmt.Errorf("%q: a key besides the modifiers is needed", text)
+	}
+	return combo, nil
+}
+
+// String returns the combo in the form ParseCombo reads, with the modifiers in a fixed order.
+func (c Combo) String() string {
+	var parts []string
+	for _, modifier := range []struct {
+		bit  Modifier
+		name string
+	}{{Ctrl, "Ctrl"}, {Alt, "Alt"}, {Shift, "Shift"}, {Win, "Win"}} {
+		if c.Modifiers&modifier.bit != 0 {
+			parts = append(parts, modifier.name)
+		}
+	}
+	return strings.Join(append(parts, c.Key.String()), "+")
+}
@@ -0,0 +1,76 @@
+// hotkeys/combo_test.go
+
+package hotkeys
+
+import "testing"
+
+func TestParseCombo(t *testing.T) {
+	tests := []struct {
+		text string
+		want Combo
+	}{
+		{"Ctrl+Alt+M", Combo{Ctrl | Alt, 'M'}},
+		{"alt + ctrl + m", Combo{Ctrl | Alt, 'M'}},
+		{"Control+Shift+F5", Combo{Ctrl | Shift, 0x74}},
+		{"Win+PageUp", Combo{Win, 0x21}},
+		{"F12", Combo{0, 0x7B}},
+		{"Ctrl+Alt+0", Combo{Ctrl | Alt, '0'}},
+		{"Ctrl+NumPad7", Combo{Ctrl, 0x67}},
+		{"Ctrl++", Combo{Ctrl, 0xBB}},
+		{"Ctrl+Plus", Combo{Ctrl, 0xBB}},
+		{"+", Combo{0, 0xBB}},
+		{"Ctrl+-", Combo{Ctrl, 0xBD}},
+	}
+	for _, tt := range tests {
+		got, err := ParseCombo(tt.text)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseCombo(%q) = %+v, %v; want %+v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseComboInvalid(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"   ",
+		"Ctrl+Alt",
+		"Ctrl+Hyper+M",
+		"Ctrl+M+N",
+		"Ctrl++M",
+		"Ctrl+",
+		"Ctrl+Ctrl+M",
+		"Ctrl+Control+M",
+		"Shift+M+shift",
+	} {
+		if combo, err := ParseCombo(text); err == nil {
+			t.Errorf("ParseCombo(%q) = %+v, want an error", text, combo)
+		}
+	}
+}
+
+func TestComboString(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"alt+ctrl+m", "Ctrl+Alt+M"},
+		{"shift+win+ctrl+alt+delete", "Ctrl+Alt+Shift+Win+Delete"},
+		{"ctrl+numpad7", "Ctrl+NumPad7"},
+		{"ctrl+f24", "Ctrl+F24"},
+		{"ctrl++", "Ctrl+="},
+		{"ctrl+comma", "Ctrl+,"},
+	}
+	for _, tt := range tests {
+		combo, err := ParseCombo(tt.text)
+		if err != nil {
+			t.Fatalf("ParseCombo(%q): %v", tt.text, err)
+		}
+		if got := combo.String(); got != tt.want {
+			t.Errorf("ParseCombo(%q).String() = %q, want %q", tt.text, got, tt.want)
+		}
+		// String is read back as the same combo
+		if again, err := ParseCombo(combo.String()); err != nil || again != combo {
+			t.Errorf("ParseCombo(%q) = %+v, %v; want %+v", combo.String(), again, err, combo)
+		}
+	}
+}
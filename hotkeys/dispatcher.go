@@ -0,0 +1,139 @@
+// hotkeys/dispatcher.go
+
+package hotkeys
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Action runs when its hotkey is pressed and returns a message to show, or "" for none
+type Action func() string
+
+// Registrar registers combos as global hotkeys. Listener is the registrar of Windows.
+type Registrar interface {
+	// Register takes combos from other applications and returns the channel
+	// they are sent on as they are pressed, which closes once the registrar
+	// is closed. Combos that can't be registered are returned as errors while
+	// the others are still sent.
+	Register(combos []Combo) (<-chan Combo, error)
+	// Close releases the combos.
+	Close()
+}
+
+// Dispatcher runs the action bound to each combo
+type Dispatcher struct {
+	bindings  map[Combo]string // action name of each combo
+	actions   map[string]Action
+	registrar Registrar
+	presses   <-chan Combo // nil until the combos are registered, and once the registrar is closed
+}
+
+// NewDispatcher binds the combos of bindings, keyed by action name, to the
+// actions of the same name. Empty combos are left unbound. Unknown actions,
+// unparsable combos and combos bound to two actions are returned as errors
+// while every other binding still applies.
+func NewDispatcher(bindings map[string]string, actions map[string]Action) (*Dispatcher, error) {
+	d := &Dispatcher{bindings: make(map[Combo]string), actions: actions}
+
+	// Bind in a fixed order so that the same combo bound twice always reports the same conflict
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		text := bindings[name]
+		if text == "" {
+			continue
+		}
+		if _, ok := actions[name]; !ok {
+			errs = append(errs, fmt.Errorf("hotkey %q: unknown action %s", text, name))
+			continue
+		}
+		combo, err := ParseCombo(text)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hotkey for %s: %w", name, err))
+			continue
+		}
+		if other, ok := d.bindings[combo]; ok {
+			errs = append(errs, fmt.Errorf("hotkey %s is bound to both %s and %s, keeping %s", combo, other, name, other))
+			continue
+		}
+		d.bindings[combo] = name
+	}
+	return d, errors.Join(errs...)
+}
+
+// Combos returns the bound combos in a fixed order.
+func (d *Dispatcher) Combos() []Combo {
+	combos := make([]Combo, 0, len(d.bindings))
+	for combo := range d.bindings {
+		combos = append(combos, combo)
+	}
+	sort.Slice(combos, func(i, j int) bool {
+		if combos[i].Key != combos[j].Key {
+			return combos[i].Key < combos[j].Key
+		}
+		return combos[i].Modifiers < combos[j].Modifiers
+	})
+	return combos
+}
+
+// Action returns the name of the action bound to combo.
+func (d *Dispatcher) Action(combo Combo) (string, bool) {
+	name, ok := d.bindings[combo]
+	return name, ok
+}
+
+// Dispatch runs the action bound to combo and returns its message. It
+// reports false when nothing is bound to combo.
+func (d *Dispatcher) Dispatch(combo Combo) (string, bool) {
+	name, ok := d.bindings[combo]
+	if !ok {
+		return "", false
+	}
+	return d.actions[name](), true
+}
+
+// Listen registers the bound combos with r, to be dispatched by Poll. Errors
+// are those of r.Register.
+func (d *Dispatcher) Listen(r Registrar) error {
+	d.registrar = r
+	presses, err := r.Register(d.Combos())
+	d.presses = presses
+	return err
+}
+
+// Poll runs the actions of the combos pressed since the last poll, without
+// waiting for presses, and returns their messages.
+func (d *Dispatcher) Poll() []string {
+	var messages []string
+	if d.presses == nil {
+		return nil
+	}
+	for {
+		select {
+		case combo, ok := <-d.presses:
+			if !ok {
+				d.presses = nil
+				return messages
+			}
+			if message, ok := d.Dispatch(combo); ok && message != "" {
+				messages = append(messages, message)
+			}
+		default:
+			return messages
+		}
+	}
+}
+
+// Close releases the combos registered by Listen.
+func (d *Dispatcher) Close() {
+	if d.registrar != nil {
+		d.registrar.Close()
+	}
+}
@@ -0,0 +1,134 @@
+// hotkeys/dispatcher_test.go
+
+package hotkeys
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeRegistrar registers every combo but those in taken, and sends the presses of the test
+type fakeRegistrar struct {
+	taken      map[Combo]bool
+	registered []Combo
+	presses    chan Combo
+	closed     bool
+}
+
+func newFakeRegistrar(taken ...Combo) *fakeRegistrar {
+	r := &fakeRegistrar{taken: make(map[Combo]bool), presses: make(chan Combo, 16)}
+	for _, combo := range taken {
+		r.taken[combo] = true
+	}
+	return r
+}
+
+func (r *fakeRegistrar) Register(combos []Combo) (<-chan Combo, error) {
+	var errs []error
+	for _, combo := range combos {
+		if r.taken[combo] {
+			errs = append(errs, errors.New(combo.String()+" is taken"))
+			continue
+		}
+		r.registered = append(r.registered, combo)
+	}
+	return r.presses, errors.Join(errs...)
+}
+
+func (r *fakeRegistrar) Close() {
+	if !r.closed {
+		r.closed = true
+		close(r.presses)
+	}
+}
+
+// press sends the combo of text.
+func (r *fakeRegistrar) press(t *testing.T, text string) {
+	t.Helper()
+	combo, err := ParseCombo(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.presses <- combo
+}
+
+func TestNewDispatcher(t *testing.T) {
+	actions := map[string]Action{
+		"zoomIn":  func() string { return "in" },
+		"zoomOut": func() string { return "out" },
+		"reload":  func() string { return "" },
+	}
+	d, err := NewDispatcher(map[string]string{
+		"zoomIn":  "Ctrl+Alt+PageUp",
+		"zoomOut": "alt+ctrl+pageup", // the same combo, written otherwise
+		"reload":  "Ctrl+Alt+Hyper",
+		"unknown": "Ctrl+U",
+		"unbound": "",
+	}, actions)
+	if err == nil {
+		t.Fatal("NewDispatcher reported no errors")
+	}
+	for _, want := range []string{"bound to both zoomIn and zoomOut", "unknown key Hyper", "unknown action unknown"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("NewDispatcher error %q doesn't mention %q", err, want)
+		}
+	}
+
+	// The valid bindings still apply, the first of a duplicate being kept
+	combos := d.Combos()
+	if want := []Combo{{Ctrl | Alt, 0x21}}; !slices.Equal(combos, want) {
+		t.Errorf("Combos = %v, want %v", combos, want)
+	}
+	if name, ok := d.Action(Combo{Ctrl | Alt, 0x21}); !ok || name != "zoomIn" {
+		t.Errorf("Action = %s, %v; want zoomIn", name, ok)
+	}
+}
+
+func TestDispatcherPoll(t *testing.T) {
+	var reloads int
+	actions := map[string]Action{
+		"zoomIn":  func() string { return "Zoom: 2×" },
+		"zoomOut": func() string { return "Zoom: 1×" },
+		"reload":  func() string { reloads++; return "" },
+	}
+	d, err := NewDispatcher(map[string]string{"zoomIn": "Ctrl+PageUp", "zoomOut": "Ctrl+PageDown", "reload": "Ctrl+R"}, actions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messages := d.Poll(); messages != nil {
+		t.Errorf("Poll before Listen = %q, want none", messages)
+	}
+
+	r := newFakeRegistrar(Combo{Ctrl, 0x22})
+	if err := d.Listen(r); err == nil || !strings.Contains(err.Error(), "Ctrl+PageDown is taken") {
+		t.Errorf("Listen = %v, want the taken combo", err)
+	}
+	if want := []Combo{{Ctrl, 0x21}, {Ctrl, 'R'}}; !slices.Equal(r.registered, want) {
+		t.Errorf("registered %v, want %v", r.registered, want)
+	}
+
+	// Presses are dispatched in order, silent actions show nothing and unbound combos are ignored
+	r.press(t, "Ctrl+PageUp")
+	r.press(t, "Ctrl+R")
+	r.press(t, "Ctrl+Shift+R")
+	r.press(t, "Ctrl+PageUp")
+	if messages, want := d.Poll(), []string{"Zoom: 2×", "Zoom: 2×"}; !slices.Equal(messages, want) {
+		t.Errorf("Poll = %q, want %q", messages, want)
+	}
+	if reloads != 1 {
+		t.Errorf("reload ran %d times, want 1", reloads)
+	}
+	if messages := d.Poll(); messages != nil {
+		t.Errorf("Poll without presses = %q, want none", messages)
+	}
+
+	d.Close()
+	if !r.closed {
+		t.Errorf("Close didn't close the registrar")
+	}
+	if messages := d.Poll(); messages != nil {
+		t.Errorf("Poll after Close = %q, want none", messages)
+	}
+}
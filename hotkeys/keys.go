@@ -0,0 +1,120 @@
+// hotkeys/keys.go
+
+package hotkeys
+
+import "fmt"
+
+// Key is a Windows virtual-key code
+type Key uint32
+
+// keyNames maps the accepted key names, lower case, to their virtual-key codes
+var keyNames = map[string]Key{
+	"backspace":  0x08,
+	"tab":        0x09,
+	"enter":      0x0D,
+	"return":     0x0D,
+	"pause":      0x13,
+	"capslock":   0x14,
+	"escape":     0x1B,
+	"esc":        0x1B,
+	"space":      0x20,
+	"pageup":     0x21,
+	"pagedown":   0x22,
+	"end":        0x23,
+	"home":       0x24,
+	"left":       0x25,
+	"up":         0x26,
+	"right":      0x27,
+	"down":       0x28,
+	"insert":     0x2D,
+	"delete":     0x2E,
+	"numlock":    0x90,
+	"scrolllock": 0x91,
+	";":          0xBA,
+	"=":          0xBB,
+	"plus":       0xBB,
+	",":          0xBC,
+	"comma":      0xBC,
+	"-":          0xBD,
+	"minus":      0xBD,
+	".":          0xBE,
+	"period":     0xBE,
+	"/":          0xBF,
+	"`":          0xC0,
+	"backquote":  0xC0,
+	"[":          0xDB,
+	"\\":         0xDC,
+	"]":          0xDD,
+	"'":          0xDE,
+	"numpadmul":  0x6A,
+	"numpadadd":  0x6B,
+	"numpadsub":  0x6D,
+	"numpaddot":  0x6E,
+	"numpaddiv":  0x6F,
+}
+
+// keyLabels are the names keys are written with, besides letters and digits
+var keyLabels = map[Key]string{
+	0x08: "Backspace",
+	0x09: "Tab",
+	0x0D: "Enter",
+	0x13: "Pause",
+	0x14: "CapsLock",
+	0x1B: "Escape",
+	0x20: "Space",
+	0x21: "PageUp",
+	0x22: "PageDown",
+	0x23: "End",
+	0x24: "Home",
+	0x25: "Left",
+	0x26: "Up",
+	0x27: "Right",
+	0x28: "Down",
+	0x2D: "Insert",
+	0x2E: "Delete",
+	0x6A: "NumPadMul",
+	0x6B: "NumPadAdd",
+	0x6D: "NumPadSub",
+	0x6E: "NumPadDot",
+	0x6F: "NumPadDiv",
+	0x90: "NumLock",
+	0x91: "ScrollLock",
+	0xBA: ";",
+	0xBB: "=",
+	0xBC: ",",
+	0xBD: "-",
+	0xBE: ".",
+	0xBF: "/",
+	0xC0: "`",
+	0xDB: "[",
+	0xDC: "\\",
+	0xDD: "]",
+	0xDE: "'",
+}
+
+func init() {
+	// Letters and digits are their own virtual-key codes
+	for c := 'A'; c <= 'Z'; c++ {
+		keyNames[string(c+'a'-'A')] = Key(c)
+	}
+	for c := '0'; c <= '9'; c++ {
+		keyNames[string(c)] = Key(c)
+		keyNames[fmt.Sprintf("numpad%c", c)] = Key(0x60 + c - '0')
+		keyLabels[Key(0x60+c-'0')] = fmt.Sprintf("NumPad%c", c)
+	}
+	for n := 1; n <= 24; n++ {
+		keyNames[fmt.Sprintf("f%d", n)] = Key(0x70 + n - 1)
+		keyLabels[Key(0x70+n-1)] = fmt.Sprintf("F%d", n)
+	}
+}
+
+// String returns the name of the key.
+func (k Key) String() string {
+	if label, ok := keyLabels[k]; ok {
+		return label
+	}
+	if (k >= 'A' && k <= 'Z') || (k >= '0' && k <= '9') {
+		return string(rune(k))
+	}
+	return fmt.Sprintf("0x%02X", uint32(k))
+}
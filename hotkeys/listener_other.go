@@ -0,0 +1,23 @@
+//go:build !windows
+
+// hotkeys/listener_other.go
+
+package hotkeys
+
+import "errors"
+
+// Listener receives global hotkeys, which are only available on Windows
+type Listener struct{}
+
+// NewListener returns a listener that fails to register anything.
+func NewListener() *Listener {
+	return &Listener{}
+}
+
+// Register fails outside of Windows.
+func (l *Listener) Register(combos []Combo) (<-chan Combo, error) {
+	return nil, errors.New("global hotkeys are only supported on Windows")
+}
+
+// Close does nothing outside of Windows.
+func (l *Listener) Close() {}
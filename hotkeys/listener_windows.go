@@ -0,0 +1,106 @@
+// hotkeys/listener_windows.go
+
+package hotkeys
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	wmHotkey    = 0x0312
+	wmQuit      = 0x0012
+	modNoRepeat = 0x4000 // MOD_NOREPEAT, holding the keys down doesn't repeat the hotkey
+)
+
+var (
+	modUser32              = windows.NewLazySystemDLL("user32.dll")
+	procRegisterHotKey     = modUser32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = modUser32.NewProc("UnregisterHotKey")
+	procGetMessage         = modUser32.NewProc("GetMessageW")
+	procPostThreadMessageW = modUser32.NewProc("PostThreadMessageW")
+)
+
+// message is a Windows MSG
+type message struct {
+	hwnd    uintptr
+	message uint32
+	wParam  uintptr
+	lParam  uintptr
+	time    uint32
+	pt      struct{ x, y int32 }
+	private uint32
+}
+
+// Listener receives global hotkeys on a thread of its own, since hotkeys are
+// posted to the message queue of the thread that registered them
+type Listener struct {
+	threadID uint32
+}
+
+// NewListener returns a listener with no hotkeys registered.
+func NewListener() *Listener {
+	return &Listener{}
+}
+
+// Register registers combos as global hotkeys, which are then taken from
+// every other application, and listens for them. Combos that can't be
+// registered, usually because another application holds them, are returned
+// as errors while the others are still listened for.
+func (l *Listener) Register(combos []Combo) (<-chan Combo, error) {
+	presses := make(chan Combo, 16)
+	started := make(chan error)
+
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer close(presses)
+		l.threadID = windows.GetCurrentThreadId()
+
+		var errs []error
+		registered := make(map[uintptr]Combo)
+		for i, combo := range combos {
+			id := uintptr(i + 1)
+			ok, _, err := procRegisterHotKey.Call(0, id, uintptr(combo.Modifiers)|modNoRepeat, uintptr(combo.Key))
+			if ok == 0 {
+				errs = append(errs, fmt.Errorf("failed to register hotkey %s: %w", combo, err))
+				continue
+			}
+			registered[id] = combo
+		}
+		defer func() {
+			for id := range registered {
+				procUnregisterHotKey.Call(0, id)
+			}
+		}()
+		started <- errors.Join(errs...)
+
+		var msg message
+		for {
+			// GetMessage returns 0 for WM_QUIT and -1 on errors
+			ret, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&msg)), 0, 0, 0)
+			if int32(ret) <= 0 {
+				return
+			}
+			if msg.message == wmHotkey {
+				if combo, ok := registered[msg.wParam]; ok {
+					select {
+					case presses <- combo:
+					default: // the render loop is behind, drop the press rather than block
+					}
+				}
+			}
+		}
+	}()
+
+	return presses, <-started
+}
+
+// Close unregisters the hotkeys and stops the listener.
+func (l *Listener) Close() {
+	procPostThreadMessageW.Call(uintptr(l.threadID), wmQuit, 0, 0)
+}
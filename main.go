@@ -30,11 +30,16 @@ func main() {
 	log.Println("Application started.")
 
 	// Load configuration
-	cfg, err := config.LoadConfig("settings.yaml")
+	cfg, err := config.LoadConfig(config.SettingsFile)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 	log.Printf("Configuration loaded: %+v\n", cfg)
+	for setting, value := range cfg.Layers {
+		if !globals.SetSetting(setting, value) {
+			log.Printf("Unknown layer setting %s in %s", setting, config.SettingsFile)
+		}
+	}
 
 	// Initialize GDI+
 	log.Println("Initializing GDI+...")
@@ -51,8 +56,7 @@ func main() {
 		log.Fatalf("Failed to get module handle: %v", syscall.GetLastError())
 	}
 
-	nipsFolderPath := "./config/nips/"
-	err = types.LoadNipRules(nipsFolderPath)
+	err = types.LoadNipRules(config.NipsFolder)
 	if err != nil {
 		log.Fatalf("Failed to load NIP rules: %v", err)
 	}
@@ -67,33 +71,9 @@ func main() {
 		return
 	}
 
-	// Initialize memory with selected process
-	d2r, err := utils.NewClassMemory(selectedProcess.ExeName, 0)
-	if err != nil {
-		log.Fatalf("Failed to initialize memory: %v", err)
-	}
-	defer d2r.Close()
-
-	// Perform Pattern Scan
-	err = memory.PatternScan(d2r)
-	if err != nil {
-		log.Fatalf("Pattern scan failed: %v", err)
-	}
-
-	// Check if player is in-game and log the result
-	unitTableOffset, exists := globals.GetOffset("unitTable")
-	if !exists {
-		log.Fatalf("main.go: Failed to retrieve 'unitTable' offset from globals.")
-	}
-	inGame, err := memory.IsInGame(d2r, unitTableOffset)
-	if err != nil {
-		log.Fatalf("Failed to check if player is in-game: %v", err)
-	}
-	if inGame {
-		log.Println("Player is currently in-game.")
-	} else {
-		log.Println("Player is not in-game.")
-	}
+	// Initialize memory with selected process. When it can't be read yet the
+	// supervisor keeps attaching, as it does after D2R restarts.
+	d2r := attachProcess(selectedProcess.ExeName)
 
 	// Create the overlay window information
 	processInfo := &globals.ProcessInfo{
@@ -107,8 +87,12 @@ func main() {
 		log.Fatalf("Overlay initialization failed: %v", err)
 	}
 
+	// Keep reading from D2R when it exits or restarts
+	supervisor := memory.NewSupervisor(selectedProcess.ExeName, d2r)
+	defer supervisor.Close()
+
 	// Start memory reading routine in a separate goroutine
-	go ui.ReadGameMemoryRoutine(d2r, cfg)
+	go ui.ReadGameMemoryRoutine(supervisor, cfg)
 
 	// Run the overlay render loop on the main goroutine
 	err = ui.RunOverlay()
@@ -119,3 +103,36 @@ func main() {
 	// After RunOverlay exits, continue with shutdown
 	log.Println("Main program execution completed.")
 }
+
+// attachProcess opens the selected D2R process, resolves its signatures and
+// logs whether the player is in game. It returns nil if any of that fails.
+func attachProcess(exeName string) utils.Process {
+	d2r, err := utils.NewClassMemory(exeName, 0)
+	if err != nil {
+		log.Printf("Failed to initialize memory: %v; waiting for D2R", err)
+		return nil
+	}
+
+	// Perform Pattern Scan
+	err = memory.PatternScan(d2r, true)
+	if err != nil {
+		log.Printf("Pattern scan failed: %v; waiting for D2R", err)
+		utils.IfError(d2r.Close(), "Failed to close D2R process handle")
+		return nil
+	}
+
+	// Check if player is in-game and log the result
+	unitTableOffset, _ := globals.GetOffset("unitTable")
+	inGame, err := memory.IsInGame(d2r, unitTableOffset)
+	if err != nil {
+		log.Printf("Failed to check if player is in-game: %v; waiting for D2R", err)
+		utils.IfError(d2r.Close(), "Failed to close D2R process handle")
+		return nil
+	}
+	if inGame {
+		log.Println("Player is currently in-game.")
+	} else {
+		log.Println("Player is not in-game.")
+	}
+	return d2r
+}
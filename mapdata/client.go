@@ -0,0 +1,173 @@
+// mapdata/client.go
+
+package mapdata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrLevelNotFound is returned when the map server has no data for a level
+var ErrLevelNotFound = errors.New("level not found")
+
+// Client fetches level data from a d2-mapserver style HTTP API:
+//
+//	GET {BaseURL}/v1/map/{seed}/{difficulty}/{level}.json
+//	GET {BaseURL}/v1/map/{seed}/{difficulty}/{level}/image
+//
+// Responses are kept under CacheDir as {seed}_{difficulty}_{level}.json and
+// .png so that every level is requested at most once.
+type Client struct {
+	BaseURL  string
+	CacheDir string
+	HTTP     *http.Client
+
+	mu     sync.Mutex
+	levels map[levelKey]*Level
+}
+
+type levelKey struct {
+	seed       uint32
+	difficulty uint16
+	level      uint32
+}
+
+func (k levelKey) String() string {
+	return fmt.Sprintf("%d_%d_%d", k.seed, k.difficulty, k.level)
+}
+
+// NewClient returns a client for the map server at baseURL caching to cacheDir.
+func NewClient(baseURL, cacheDir string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		CacheDir: cacheDir,
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+		levels:   make(map[levelKey]*Level),
+	}
+}
+
+// Level returns the collision map and presets of a level, from memory, the
+// disk cache or the map server in that order.
+func (c *Client) Level(seed uint32, difficulty uint16, levelNo uint32) (*Level, error) {
+	key := levelKey{seed, difficulty, levelNo}
+	c.mu.Lock()
+	level, ok := c.levels[key]
+	c.mu.Unlock()
+	if ok {
+		return level, nil
+	}
+
+	path := fmt.Sprintf("/v1/map/%d/%d/%d.json", seed, difficulty, levelNo)
+	data, fromCache, err := c.cached(key, ".json", path)
+	if err != nil {
+		return nil, err
+	}
+	level = &Level{}
+	if err := json.Unmarshal(data, level); err != nil {
+		// Don't keep a broken response around
+		os.Remove(c.cachePath(key, ".json"))
+		if !fromCache {
+			return nil, fmt.Errorf("level %v: %w", key, err)
+		}
+		// A cache entry that doesn't parse, e.g. from an older version, is fetched again
+		if data, err = c.download(key, ".json", path); err != nil {
+			return nil, err
+		}
+		level = &Level{}
+		if err := json.Unmarshal(data, level); err != nil {
+			os.Remove(c.cachePath(key, ".json"))
+			return nil, fmt.Errorf("level %v: %w", key, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.levels[key] = level
+	c.mu.Unlock()
+	return level, nil
+}
+
+// LevelImage returns the map server's PNG rendering of a level.
+func (c *Client) LevelImage(seed uint32, difficulty uint16, levelNo uint32) ([]byte, error) {
+	key := levelKey{seed, difficulty, levelNo}
+	data, _, err := c.cached(key, ".png", fmt.Sprintf("/v1/map/%d/%d/%d/image", seed, difficulty, levelNo))
+	return data, err
+}
+
+func (c *Client) cachePath(key levelKey, ext string) string {
+	return filepath.Join(c.CacheDir, key.String()+ext)
+}
+
+// cached returns the cache file for key, fetching path from the server when it
+// doesn't exist. It also reports whether the data came from the cache.
+func (c *Client) cached(key levelKey, ext, path string) ([]byte, bool, error) {
+	if c.CacheDir != "" {
+		if data, err := os.ReadFile(c.cachePath(key, ext)); err == nil {
+			return data, true, nil
+		}
+	}
+	data, err := c.download(key, ext, path)
+	return data, false, err
+}
+
+// download fetches path from the server and writes it to the cache file for key.
+func (c *Client) download(key levelKey, ext, path string) ([]byte, error) {
+	data, err := c.fetch(path)
+	if err != nil {
+		return nil, fmt.Errorf("level %v: %w", key, err)
+	}
+
+	if c.CacheDir != "" {
+		if err := writeFileAtomic(c.cachePath(key, ext), data); err != nil {
+			return nil, fmt.Errorf("caching level %v: %w", key, err)
+		}
+	}
+	return data, nil
+}
+
+func (c *Client) fetch(path string) ([]byte, error) {
+	if c.BaseURL == "" {
+		return nil, errors.New("no map server configured")
+	}
+	resp, err := c.HTTP.Get(c.BaseURL + path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrLevelNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("map server returned %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// writeFileAtomic writes data through a temporary file so that a crash never leaves a truncated cache entry.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,113 @@
+// mapdata/client_test.go
+
+package mapdata
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestServer serves testdata/blood_moor.json as level 2 of seed 1234 on hell,
+// and 404 for every other level. It counts the requests it gets.
+func newTestServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "blood_moor.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v1/map/1234/2/2.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func checkBloodMoor(t *testing.T, level *Level) {
+	t.Helper()
+	if level.ID != 2 || level.Name != "Blood Moor" || level.Offset != (Point{5080, 5560}) || level.Size != (Size{8, 4}) {
+		t.Errorf("level = %d %q at %v size %v", level.ID, level.Name, level.Offset, level.Size)
+	}
+	if len(level.Exits()) != 2 || len(level.Waypoints()) != 1 || len(level.Map) != 4 {
+		t.Errorf("level has %d exits, %d waypoints and %d map rows, want 2, 1 and 4", len(level.Exits()), len(level.Waypoints()), len(level.Map))
+	}
+}
+
+func TestClientLevelCache(t *testing.T) {
+	server, requests := newTestServer(t)
+	cacheDir := t.TempDir()
+
+	// A miss is fetched and written to the cache
+	client := NewClient(server.URL, cacheDir)
+	level, err := client.Level(1234, 2, 2)
+	if err != nil {
+		t.Fatalf("Level: %v", err)
+	}
+	checkBloodMoor(t, level)
+	if _, err := os.Stat(filepath.Join(cacheDir, "1234_2_2.json")); err != nil {
+		t.Errorf("level wasn't cached: %v", err)
+	}
+	if again, err := client.Level(1234, 2, 2); err != nil || again != level {
+		t.Errorf("Level didn't return the level in memory: %v", err)
+	}
+
+	// A new client, as after a restart, reads it from the disk cache
+	level, err = NewClient(server.URL, cacheDir).Level(1234, 2, 2)
+	if err != nil {
+		t.Fatalf("Level from cache: %v", err)
+	}
+	checkBloodMoor(t, level)
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+}
+
+func TestClientLevelNotFound(t *testing.T) {
+	server, requests := newTestServer(t)
+	cacheDir := t.TempDir()
+	client := NewClient(server.URL, cacheDir)
+
+	if _, err := client.Level(1234, 2, 133); !errors.Is(err, ErrLevelNotFound) {
+		t.Errorf("Level = %v, want ErrLevelNotFound", err)
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "1234_2_133.json")); !os.IsNotExist(err) {
+		t.Errorf("a missing level was cached")
+	}
+	// Missing levels aren't remembered, the server may get them later
+	client.Level(1234, 2, 133)
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+}
+
+func TestClientCorruptCache(t *testing.T) {
+	server, requests := newTestServer(t)
+	cacheDir := t.TempDir()
+	cachePath := filepath.Join(cacheDir, "1234_2_2.json")
+	if err := os.WriteFile(cachePath, []byte(`{"id": 2, "name": "Blood`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	level, err := NewClient(server.URL, cacheDir).Level(1234, 2, 2)
+	if err != nil {
+		t.Fatalf("Level: %v", err)
+	}
+	checkBloodMoor(t, level)
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+	fixture, _ := os.ReadFile(filepath.Join("testdata", "blood_moor.json"))
+	if cached, err := os.ReadFile(cachePath); err != nil || string(cached) != string(fixture) {
+		t.Errorf("corrupt cache entry wasn't replaced: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// mapdata/level.go
+
+package mapdata
+
+import "strings"
+
+// Object types reported by the map server
+const (
+	ObjectExit   = "exit"
+	ObjectNPC    = "npc"
+	ObjectObject = "object"
+)
+
+// Point is a position in game coordinates
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Size is the extent of a level in game coordinates
+type Size struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Object is a preset placed in a level: an exit to another level, an NPC or an object
+type Object struct {
+	ID         int    `json:"id"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	X          int    `json:"x"`
+	Y          int    `json:"y"`
+	IsGoodExit bool   `json:"isGoodExit"`
+}
+
+// IsWaypoint reports whether the object is a waypoint
+func (o Object) IsWaypoint() bool {
+	return o.Type == ObjectObject && strings.Contains(strings.ToLower(o.Name), "waypoint")
+}
+
+// Level is the map server's description of one level of a game. Object
+// positions are relative to Offset, the level origin in game coordinates.
+//
+// Map holds the collision grid one row at a time, run-length encoded as
+// alternating counts of blocked and walkable cells starting with blocked.
+type Level struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Offset  Point    `json:"offset"`
+	Size    Size     `json:"size"`
+	Objects []Object `json:"objects"`
+	Map     [][]int  `json:"map"`
+}
+
+// ObjectsOfType returns the objects of the given type.
+func (l *Level) ObjectsOfType(objectType string) []Object {
+	var objects []Object
+	for _, o := range l.Objects {
+		if o.Type == objectType {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// Exits returns the level's exits to adjacent levels.
+func (l *Level) Exits() []Object {
+	return l.ObjectsOfType(ObjectExit)
+}
+
+// Waypoints returns the level's waypoints.
+func (l *Level) Waypoints() []Object {
+	var waypoints []Object
+	for _, o := range l.Objects {
+		if o.IsWaypoint() {
+			waypoints = append(waypoints, o)
+		}
+	}
+	return waypoints
+}
+
+// CollisionGrid is a decoded collision map, Width x Height cells in row order
+type CollisionGrid struct {
+	Width    int
+	Height   int
+	Walkable []bool
+}
+
+// At reports whether the cell at x, y relative to the level origin is walkable.
+// Cells outside the grid are blocked.
+func (g *CollisionGrid) At(x, y int) bool {
+	if x < 0 || y < 0 || x >= g.Width || y >= g.Height {
+		return false
+	}
+	return g.Walkable[y*g.Width+x]
+}
+
+// Collision decodes the run-length encoded collision map. Rows that end
+// early are padded with blocked cells and runs past the width are cut off.
+func (l *Level) Collision() *CollisionGrid {
+	width, height := l.Size.Width, l.Size.Height
+	if height == 0 {
+		height = len(l.Map)
+	}
+	if width == 0 {
+		for _, row := range l.Map {
+			rowWidth := 0
+			for _, run := range row {
+				rowWidth += max(run, 0)
+			}
+			width = max(width, rowWidth)
+		}
+	}
+
+	grid := &CollisionGrid{Width: width, Height: height, Walkable: make([]bool, width*height)}
+	for y, row := range l.Map {
+		if y >= height {
+			break
+		}
+		x := 0
+		for i, run := range row {
+			walkable := i%2 == 1
+			for end := min(x+max(run, 0), width); x < end; x++ {
+				grid.Walkable[y*width+x] = walkable
+			}
+		}
+	}
+	return grid
+}
+
+// Size returns the width and height of the grid.
+func (g *CollisionGrid) Size() (int, int) {
+	return g.Width, g.Height
+}
@@ -0,0 +1,111 @@
+// mapdata/markers.go
+
+package mapdata
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/object"
+)
+
+// MarkerKind is what a marker points at
+type MarkerKind int
+
+const (
+	MarkerExit MarkerKind = iota
+	MarkerWaypoint
+	MarkerQuest
+)
+
+// Marker is a labelled point of interest in a level, in game coordinates
+type Marker struct {
+	Kind  MarkerKind
+	Label string
+	X     int
+	Y     int
+	// TargetLevel and IsGoodExit describe where an exit leads
+	TargetLevel int
+	IsGoodExit  bool
+}
+
+// questObjects are the objects a quest needs to be found, with their labels
+var questObjects = map[object.Name]string{
+	object.YetAnotherTome:   "Summoner's Journal",
+	object.HoradricOrifice:  "Horadric Orifice",
+	object.InifussTree:      "Tree of Inifuss",
+	object.CairnStoneAlpha:  "Cairn Stones",
+	object.CairnStoneBeta:   "Cairn Stones",
+	object.CairnStoneGamma:  "Cairn Stones",
+	object.CairnStoneDelta:  "Cairn Stones",
+	object.CairnStoneLambda: "Cairn Stones",
+	object.CairnStoneTheta:  "Cairn Stones",
+}
+
+// ObjectMarker returns the marker for the object txtFileNo at x, y, if it is a
+// waypoint or a quest object.
+func ObjectMarker(txtFileNo int, x, y int) (Marker, bool) {
+	name := object.Name(txtFileNo)
+	if label, ok := questObjects[name]; ok {
+		return Marker{Kind: MarkerQuest, Label: label, X: x, Y: y}, true
+	}
+	if (data.Object{Name: name}).IsWaypoint() {
+		return Marker{Kind: MarkerWaypoint, Label: "Waypoint", X: x, Y: y}, true
+	}
+	return Marker{}, false
+}
+
+// IsMarkerObject reports whether the object txtFileNo gets a marker.
+func IsMarkerObject(txtFileNo int) bool {
+	_, ok := ObjectMarker(txtFileNo, 0, 0)
+	return ok
+}
+
+// LevelName returns the display name of a level.
+func LevelName(levelNo int) string {
+	if a, ok := area.Areas[area.ID(levelNo)]; ok && a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("Level %d", levelNo)
+}
+
+// Markers returns the exits, waypoints and quest objects of the level. Quest
+// objects made of several presets, such as the Cairn Stones, get a single
+// marker at their center.
+func (l *Level) Markers() []Marker {
+	var markers []Marker
+	grouped := make(map[string][]Marker)
+	for _, o := range l.Objects {
+		x, y := l.Offset.X+o.X, l.Offset.Y+o.Y
+		switch o.Type {
+		case ObjectExit:
+			markers = append(markers, Marker{Kind: MarkerExit, Label: LevelName(o.ID), X: x, Y: y, TargetLevel: o.ID, IsGoodExit: o.IsGoodExit})
+		case ObjectObject:
+			if marker, ok := ObjectMarker(o.ID, x, y); ok {
+				if marker.Kind == MarkerQuest {
+					grouped[marker.Label] = append(grouped[marker.Label], marker)
+				} else {
+					markers = append(markers, marker)
+				}
+			}
+		}
+	}
+
+	// Groups are added by label, so that the markers come in the same order every time
+	for _, label := range slices.Sorted(maps.Keys(grouped)) {
+		group := grouped[label]
+		center := group[0]
+		center.X, center.Y = 0, 0
+		for _, marker := range group {
+			center.X += marker.X
+			center.Y += marker.Y
+		}
+		center.X /= len(group)
+		center.Y /= len(group)
+		markers = append(markers, center)
+	}
+	return markers
+}
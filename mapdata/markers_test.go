@@ -0,0 +1,37 @@
+// mapdata/markers_test.go
+
+package mapdata
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/object"
+)
+
+func TestLevelMarkersOrder(t *testing.T) {
+	level := &Level{
+		Offset: Point{X: 1000, Y: 2000},
+		Objects: []Object{
+			{ID: int(object.InifussTree), Type: ObjectObject, X: 50, Y: 50},
+			{ID: int(object.CairnStoneAlpha), Type: ObjectObject, X: 10, Y: 10},
+			{ID: 3, Type: ObjectExit, X: 0, Y: 5, IsGoodExit: true},
+			{ID: int(object.YetAnotherTome), Type: ObjectObject, X: 70, Y: 80},
+			{ID: int(object.CairnStoneBeta), Type: ObjectObject, X: 20, Y: 30},
+			{ID: int(object.HoradricOrifice), Type: ObjectObject, X: 90, Y: 90},
+		},
+	}
+	want := []Marker{
+		{Kind: MarkerExit, Label: LevelName(3), X: 1000, Y: 2005, TargetLevel: 3, IsGoodExit: true},
+		{Kind: MarkerQuest, Label: "Cairn Stones", X: 1015, Y: 2020},
+		{Kind: MarkerQuest, Label: "Horadric Orifice", X: 1090, Y: 2090},
+		{Kind: MarkerQuest, Label: "Summoner's Journal", X: 1070, Y: 2080},
+		{Kind: MarkerQuest, Label: "Tree of Inifuss", X: 1050, Y: 2050},
+	}
+	// Map iteration order is random, a few runs would show a different order
+	for i := 0; i < 20; i++ {
+		if got := level.Markers(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Markers() = %+v\nwant %+v", got, want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// memory/errors.go
+package memory
+
+import (
+	"GalyMap/utils"
+	"errors"
+)
+
+var (
+	// ErrNotInGame means no player unit was found, D2R is in the menus or loading
+	ErrNotInGame = errors.New("not in game")
+	// ErrSignatureMissing means a signature didn't match the D2R module, or not as many times as expected,
+	// or an offset the readers need has none
+	ErrSignatureMissing = errors.New("signature missing")
+
+	// ErrReadFault and ErrStalePointer are re-exported so that the tick loop
+	// can match every error ReadGameMemory returns against this package
+	ErrReadFault    = utils.ErrReadFault
+	ErrStalePointer = utils.ErrStalePointer
+)
@@ -0,0 +1,133 @@
+// memory/layouts.go
+package memory
+
+import "GalyMap/utils"
+
+// Memory layouts of the D2R structures the readers use, decoded with utils.Decode.
+// Pointer fields are uint64 as D2R is a 64-bit process. After a patch that moves
+// a field, its offset only needs updating here.
+
+// Unit types, also the index of each type's table in the unit hash table
+const (
+	unitPlayer  = 0
+	unitMonster = 1
+	unitObject  = 2
+	unitMissile = 3
+	unitItem    = 4
+
+	// unitTableSize is the size of one type's table: 128 bucket pointers
+	unitTableSize = 128 * 8
+)
+
+// UnitAny is the header shared by every unit type
+type UnitAny struct {
+	Type       uint32 `offset:"0x00"`
+	TxtFileNo  uint32 `offset:"0x04"`
+	UnitId     uint32 `offset:"0x08"`
+	Mode       uint32 `offset:"0x0C"` // item location for items
+	UnitData   uint64 `offset:"0x10"` // PlayerData, MonsterData, ObjectData or ItemData; player name for players
+	Act        uint64 `offset:"0x20"`
+	Path       uint64 `offset:"0x38"` // Path for players, monsters and missiles; StaticPath for objects and items
+	StatListEx uint64 `offset:"0x88"`
+	Inventory  uint64 `offset:"0x90"`
+	Next       uint64 `offset:"0x150"` // next unit in the same hash bucket
+	IsCorpse   uint8  `offset:"0x1A6"` // of players and monsters alike
+}
+
+// Path is the dynamic path of players, monsters and missiles. Positions are
+// a whole tile plus a 16-bit fraction.
+type Path struct {
+	XOffset uint16 `offset:"0x00"`
+	X       uint16 `offset:"0x02"`
+	YOffset uint16 `offset:"0x04"`
+	Y       uint16 `offset:"0x06"`
+	Room1   uint64 `offset:"0x20"`
+}
+
+// Position returns the unit position including the fractional offsets.
+func (p Path) Position() (float64, float64) {
+	return float64(p.X) + float64(p.XOffset)/65536.0, float64(p.Y) + float64(p.YOffset)/65536.0
+}
+
+// StaticPath is the path of objects and items, which only sit on whole tiles
+type StaticPath struct {
+	X uint16 `offset:"0x10"`
+	Y uint16 `offset:"0x14"`
+}
+
+type Room1 struct {
+	Room2 uint64 `offset:"0x18"`
+}
+
+type Room2 struct {
+	Level uint64 `offset:"0x90"`
+}
+
+type Level struct {
+	LevelNo uint32 `offset:"0x1F8"`
+}
+
+type Act struct {
+	MapSeed uint32 `offset:"0x1C"`
+	ActMisc uint64 `offset:"0x78"`
+}
+
+type ActMisc struct {
+	Difficulty    uint16 `offset:"0x830"`
+	InitSeedHash1 uint32 `offset:"0x840"`
+	InitSeedHash2 uint32 `offset:"0x844"`
+	EndSeedHash1  uint32 `offset:"0x868"`
+}
+
+// Inventory holds the fields used to tell the local player apart from other players
+type Inventory struct {
+	BaseCheck    uint16 `offset:"0x30"`
+	BaseCheckExp uint16 `offset:"0x70"`
+}
+
+type StatListEx struct {
+	Stats       uint64 `offset:"0x30"`
+	StatCount   uint32 `offset:"0x38"`
+	StatsEx     uint64 `offset:"0x88"`
+	StatExCount uint32 `offset:"0x90"`
+}
+
+// statListExOwnerFlags is the StatListEx field that marks revived monsters.
+// It is read on its own to keep StatListEx reads small.
+const statListExOwnerFlags = 0xAC8 + 0xC
+
+// Stat is one entry of a stat array
+type Stat struct {
+	Layer uint16 `offset:"0x00"`
+	Id    uint16 `offset:"0x02"`
+	Value uint32 `offset:"0x04"`
+}
+
+// statSize is the stride of a stat array
+const statSize = 8
+
+// maxStatCount bounds StatListEx.StatCount, larger counts come from freed stat lists
+const maxStatCount = utils.MaxStructs
+
+type MonsterData struct {
+	OwnerId     uint32 `offset:"0x0C"`
+	IsUnique    uint16 `offset:"0x18"`
+	MonsterFlag uint8  `offset:"0x1A"`
+	// SuperUniqueId indexes superuniques.txt when MonsterFlag has monsterSuperUnique set
+	SuperUniqueId uint16 `offset:"0x2A"`
+}
+
+// monsterSuperUnique is the MonsterFlag bit of super unique monsters
+const monsterSuperUnique = 0x02
+
+type ObjectData struct {
+	InteractType uint8    `offset:"0x08"`
+	ShrineFlag   uint16   `offset:"0x09"`
+	Owner        [32]byte `offset:"0x34"` // name of the player who opened a town portal
+}
+
+type ItemData struct {
+	Quality       uint32 `offset:"0x00"`
+	Flags         uint32 `offset:"0x18"`
+	UniqueOrSetId uint32 `offset:"0x34"`
+}
@@ -0,0 +1,69 @@
+// memory/layouts_test.go
+
+package memory
+
+import (
+	"testing"
+
+	"GalyMap/utils"
+)
+
+// TestLayouts follows a player unit from the unit table down to its level
+// and its act, as ReadGameMemory does, and decodes every layout on the way.
+func TestLayouts(t *testing.T) {
+	fp := newUnitProcess(0x4000)
+	block := func(i int) uintptr { return heapAddress(t, fp, i) }
+
+	unit, path, room1, room2, level, act, actMisc, statList, stats := block(0), block(1), block(2), block(3), block(4), block(5), block(6), block(24), block(25)
+	putUnit(fp, testUnit{address: unit, unitType: unitPlayer, txtFileNo: 3, unitID: 7, mode: 1, path: path, statList: statList})
+	fp.PutUint64(unit+0x20, uint64(act))
+	fp.PutUint16(path+0x00, 0x8000)
+	fp.PutUint16(path+0x02, 5100)
+	fp.PutUint16(path+0x04, 0x4000)
+	fp.PutUint16(path+0x06, 5200)
+	fp.PutUint64(path+0x20, uint64(room1))
+	fp.PutUint64(room1+0x18, uint64(room2))
+	fp.PutUint64(room2+0x90, uint64(level))
+	fp.PutUint32(level+0x1F8, 39)
+	fp.PutUint32(act+0x1C, 0x1A2B3C4D)
+	fp.PutUint64(act+0x78, uint64(actMisc))
+	fp.PutUint16(actMisc+0x830, 2)
+	fp.PutUint32(actMisc+0x840, 0x0BADF00D)
+	fp.PutUint32(actMisc+0x844, 0x12345678)
+	fp.PutUint32(actMisc+0x868, 0xCAFEBABE)
+	fp.PutUint64(statList+0x30, uint64(stats))
+	fp.PutUint32(statList+0x38, 2)
+	fp.Put(stats, []byte{0, 0, 12, 0, 90, 0, 0, 0, 0, 0, 6, 0, 0, 0x64, 0, 0})
+
+	u, err := utils.ReadStruct[UnitAny](fp, unit)
+	if err != nil || u.Type != unitPlayer || u.TxtFileNo != 3 || u.UnitId != 7 || u.Mode != 1 {
+		t.Fatalf("UnitAny = %+v, %v", u, err)
+	}
+	p, err := utils.ReadStruct[Path](fp, uintptr(u.Path))
+	if x, y := p.Position(); err != nil || x != 5100.5 || y != 5200.25 {
+		t.Errorf("Path position = %g, %g, %v; want 5100.5, 5200.25", x, y, err)
+	}
+	r1, _ := utils.ReadStruct[Room1](fp, uintptr(p.Room1))
+	r2, _ := utils.ReadStruct[Room2](fp, uintptr(r1.Room2))
+	if l, err := utils.ReadStruct[Level](fp, uintptr(r2.Level)); err != nil || l.LevelNo != 39 {
+		t.Errorf("Level through the rooms = %+v, %v; want level 39", l, err)
+	}
+
+	a, err := utils.ReadStruct[Act](fp, uintptr(u.Act))
+	if err != nil || a.MapSeed != 0x1A2B3C4D {
+		t.Errorf("Act = %+v, %v", a, err)
+	}
+	misc, err := utils.ReadStruct[ActMisc](fp, uintptr(a.ActMisc))
+	if want := (ActMisc{Difficulty: 2, InitSeedHash1: 0x0BADF00D, InitSeedHash2: 0x12345678, EndSeedHash1: 0xCAFEBABE}); err != nil || misc != want {
+		t.Errorf("ActMisc = %+v, %v; want %+v", misc, err, want)
+	}
+
+	list, err := utils.ReadStruct[StatListEx](fp, uintptr(u.StatListEx))
+	if err != nil || list.Stats != uint64(stats) || list.StatCount != 2 {
+		t.Fatalf("StatListEx = %+v, %v", list, err)
+	}
+	got, err := utils.ReadStructs[Stat](fp, uintptr(list.Stats), int(list.StatCount), statSize)
+	if want := []Stat{{Id: 12, Value: 90}, {Id: 6, Value: 0x6400}}; err != nil || len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("stats = %+v, %v; want %+v", got, err, want)
+	}
+}
@@ -0,0 +1,111 @@
+// memory/mapseed.go
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// SeedProvider derives the map seed of a game from the seed hashes in ActMisc
+type SeedProvider interface {
+	Name() string
+	MapSeed(initSeedHash1, initSeedHash2, endSeedHash1 uint32) (uint32, error)
+}
+
+var errNoSeed = errors.New("no map seed matches the seed hashes")
+
+const (
+	// D2R hashes the map seed with endSeedHash1 = seed*seedHashMultiplier + seedHashIncrement
+	seedHashMultiplier = 0x6AC690C5
+	seedHashIncrement  = 666
+	// seedHashInverse is the multiplicative inverse of seedHashMultiplier modulo 2^32
+	seedHashInverse = 0x8A3E6E0D
+)
+
+// GoSeedProvider inverts the seed hash in pure Go. The multiplier is odd, so
+// every endSeedHash1 has exactly one seed and no search is needed. As with
+// get_seed in rustdecrypt.dll, the map seed is that seed xor initSeedHash1.
+type GoSeedProvider struct{}
+
+func (GoSeedProvider) Name() string {
+	return "go"
+}
+
+func (GoSeedProvider) MapSeed(initSeedHash1, initSeedHash2, endSeedHash1 uint32) (uint32, error) {
+	seed := (endSeedHash1 - seedHashIncrement) * seedHashInverse
+	if seed == 0 || seed^initSeedHash1 == 0 {
+		return 0, errNoSeed
+	}
+	return seed ^ initSeedHash1, nil
+}
+
+// SeedProviders tries each provider in turn until one of them finds the seed
+type SeedProviders []SeedProvider
+
+func (p SeedProviders) Name() string {
+	return fmt.Sprintf("%d providers", len(p))
+}
+
+func (p SeedProviders) MapSeed(initSeedHash1, initSeedHash2, endSeedHash1 uint32) (uint32, error) {
+	var errs []error
+	for _, provider := range p {
+		seed, err := provider.MapSeed(initSeedHash1, initSeedHash2, endSeedHash1)
+		if err == nil {
+			return seed, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+	return 0, errors.Join(errs...)
+}
+
+// verifiedSeedProvider checks the seeds of a provider against a reference
+// provider and logs the ones that differ, since maps drawn from the wrong seed
+// don't line up. The seed of the provider is used either way.
+type verifiedSeedProvider struct {
+	provider  SeedProvider
+	reference SeedProvider
+}
+
+func (p verifiedSeedProvider) Name() string {
+	return fmt.Sprintf("%s verified by %s", p.provider.Name(), p.reference.Name())
+}
+
+func (p verifiedSeedProvider) MapSeed(initSeedHash1, initSeedHash2, endSeedHash1 uint32) (uint32, error) {
+	seed, err := p.provider.MapSeed(initSeedHash1, initSeedHash2, endSeedHash1)
+	if err != nil {
+		return 0, err
+	}
+	referenceSeed, referenceErr := p.reference.MapSeed(initSeedHash1, initSeedHash2, endSeedHash1)
+	switch {
+	case referenceErr != nil:
+		log.Printf("Map seed %d not verified, %s failed: %v", seed, p.reference.Name(), referenceErr)
+	case referenceSeed != seed:
+		log.Printf("ERROR: map seed %d differs from the %s seed %d for hashes 0x%08X 0x%08X 0x%08X",
+			seed, p.reference.Name(), referenceSeed, initSeedHash1, initSeedHash2, endSeedHash1)
+	}
+	return seed, nil
+}
+
+// seedProvider is used by ReadGameMemory, see defaultSeedProvider
+var seedProvider = defaultSeedProvider(false)
+
+// SetSeedProvider replaces the provider used to derive map seeds.
+func SetSeedProvider(provider SeedProvider) {
+	seedProvider = provider
+}
+
+// VerifyMapSeeds checks every map seed derived in Go against rustdecrypt.dll
+// and logs the ones that differ.
+func VerifyMapSeeds() {
+	SetSeedProvider(defaultSeedProvider(true))
+}
+
+func calculateMapSeed(InitSeedHash1, InitSeedHash2, EndSeedHash1 uint32) uint32 {
+	mapSeed, err := seedProvider.MapSeed(InitSeedHash1, InitSeedHash2, EndSeedHash1)
+	if err != nil {
+		log.Printf("ERROR: YOU HAVE AN ERROR DECRYPTING THE MAP SEED, YOUR MAPS WILL EITHER NOT APPEAR OR NOT LINE UP: %v", err)
+		return 0
+	}
+	return mapSeed
+}
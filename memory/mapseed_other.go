@@ -0,0 +1,14 @@
+//go:build !windows
+
+// memory/mapseed_other.go
+package memory
+
+import "log"
+
+// defaultSeedProvider derives seeds in Go; rustdecrypt.dll only exists on Windows.
+func defaultSeedProvider(verify bool) SeedProvider {
+	if verify {
+		log.Printf("Map seeds can't be verified without rustdecrypt.dll, which only exists on Windows")
+	}
+	return GoSeedProvider{}
+}
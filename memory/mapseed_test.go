@@ -0,0 +1,128 @@
+// memory/mapseed_test.go
+
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	d2goutils "github.com/hectorgimenez/d2go/pkg/utils"
+)
+
+// seedVectors are seed hashes with their map seed, the hashed seed xor
+// initSeedHash1. They aren't captured from games, none are at hand, so
+// TestSeedVectors checks them with the brute-force search of d2go instead,
+// and TestDLLSeedProvider with rustdecrypt.dll where it is installed.
+// initSeedHash2 takes no part in the seed and must not change it.
+var seedVectors = []struct {
+	initSeedHash1, initSeedHash2, endSeedHash1 uint32
+	mapSeed                                    uint32
+}{
+	{0x5F3A9C11, 0x2B7E1516, 0x4D697C58, 2006208183},
+	{0x0BADF00D, 0x9E3779B9, 0x7E14F6B3, 195875656},
+	{0xC0FFEE00, 0xFFFFFFFF, 0x8A611992, 1042502808},
+	{0x00000001, 0x00000002, 0xBA521DA7, 1290859944},
+	{0x7E57C0DE, 0x13572468, 0x9B2487AA, 363880718},
+}
+
+func TestSeedVectors(t *testing.T) {
+	for _, v := range seedVectors {
+		seed, ok := d2goutils.GetMapSeed(uint(v.initSeedHash1), uint(v.endSeedHash1))
+		if !ok || uint32(seed)^v.initSeedHash1 != v.mapSeed {
+			t.Errorf("d2go finds seed %d, %v for 0x%08X; want %d xor 0x%08X", seed, ok, v.endSeedHash1, v.mapSeed, v.initSeedHash1)
+		}
+	}
+}
+
+func TestGoSeedProvider(t *testing.T) {
+	for _, v := range seedVectors {
+		seed, err := GoSeedProvider{}.MapSeed(v.initSeedHash1, v.initSeedHash2, v.endSeedHash1)
+		if err != nil || seed != v.mapSeed {
+			t.Errorf("MapSeed(0x%08X, 0x%08X, 0x%08X) = %d, %v; want %d", v.initSeedHash1, v.initSeedHash2, v.endSeedHash1, seed, err, v.mapSeed)
+		}
+	}
+
+	// Seed 0, and a seed equal to initSeedHash1, give no map seed
+	for _, v := range []struct{ initSeedHash1, endSeedHash1 uint32 }{{0x1234, seedHashIncrement}, {0x12345678, 0x03BA0CF2}} {
+		if seed, err := (GoSeedProvider{}).MapSeed(v.initSeedHash1, 0, v.endSeedHash1); !errors.Is(err, errNoSeed) {
+			t.Errorf("MapSeed(0x%08X, 0, 0x%08X) = %d, %v; want errNoSeed", v.initSeedHash1, v.endSeedHash1, seed, err)
+		}
+	}
+}
+
+// fixedSeedProvider returns the same seed, or error, for any hashes and counts its calls
+type fixedSeedProvider struct {
+	seed  uint32
+	err   error
+	calls *int
+}
+
+func (p fixedSeedProvider) Name() string { return "fixed" }
+
+func (p fixedSeedProvider) MapSeed(initSeedHash1, initSeedHash2, endSeedHash1 uint32) (uint32, error) {
+	if p.calls != nil {
+		*p.calls++
+	}
+	return p.seed, p.err
+}
+
+func TestSeedProviders(t *testing.T) {
+	unavailable := errors.New("not installed")
+	tests := []struct {
+		name          string
+		first, second fixedSeedProvider
+		want          uint32
+		wantErr       bool
+		wantFallback  bool // the second provider is called
+	}{
+		{"first finds the seed", fixedSeedProvider{seed: 7}, fixedSeedProvider{seed: 9}, 7, false, false},
+		{"fallback", fixedSeedProvider{err: errNoSeed}, fixedSeedProvider{seed: 9}, 9, false, true},
+		{"neither", fixedSeedProvider{err: errNoSeed}, fixedSeedProvider{err: unavailable}, 0, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tt.second.calls = &calls
+			seed, err := SeedProviders{tt.first, tt.second}.MapSeed(1, 2, 3)
+			if (err != nil) != tt.wantErr || seed != tt.want {
+				t.Errorf("MapSeed = %d, %v; want %d, error %v", seed, err, tt.want, tt.wantErr)
+			}
+			if tt.wantErr && (!errors.Is(err, errNoSeed) || !errors.Is(err, unavailable)) {
+				t.Errorf("MapSeed error %v doesn't hold the errors of both providers", err)
+			}
+			if fallback := calls > 0; fallback != tt.wantFallback {
+				t.Errorf("second provider called %d times, want called %v", calls, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestVerifiedSeedProvider(t *testing.T) {
+	unavailable := fixedSeedProvider{err: errors.New("not installed")}
+	tests := []struct {
+		name      string
+		provider  fixedSeedProvider
+		reference fixedSeedProvider
+		want      uint32
+		wantErr   bool
+		wantCalls int // of the reference
+	}{
+		{"agree", fixedSeedProvider{seed: 7}, fixedSeedProvider{seed: 7}, 7, false, 1},
+		{"mismatch keeps the seed", fixedSeedProvider{seed: 7}, fixedSeedProvider{seed: 9}, 7, false, 1},
+		{"no reference", fixedSeedProvider{seed: 7}, unavailable, 7, false, 1},
+		{"no seed", fixedSeedProvider{err: errNoSeed}, fixedSeedProvider{seed: 9}, 0, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tt.reference.calls = &calls
+			seed, err := verifiedSeedProvider{provider: tt.provider, reference: tt.reference}.MapSeed(1, 2, 3)
+			if (err != nil) != tt.wantErr || seed != tt.want {
+				t.Errorf("MapSeed = %d, %v; want %d, error %v", seed, err, tt.want, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("reference called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
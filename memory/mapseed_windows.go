@@ -0,0 +1,51 @@
+// memory/mapseed_windows.go
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+var (
+	modRustDecrypt = syscall.NewLazyDLL("rustdecrypt.dll")
+	procGetSeed    = modRustDecrypt.NewProc("get_seed")
+)
+
+// DLLSeedProvider calls get_seed in rustdecrypt.dll, if it is installed
+type DLLSeedProvider struct{}
+
+func (DLLSeedProvider) Name() string {
+	return "rustdecrypt.dll"
+}
+
+func (DLLSeedProvider) MapSeed(initSeedHash1, initSeedHash2, endSeedHash1 uint32) (uint32, error) {
+	if err := procGetSeed.Find(); err != nil {
+		return 0, err
+	}
+
+	// Call the DLL function
+	ret, _, err := procGetSeed.Call(
+		uintptr(initSeedHash1),
+		uintptr(initSeedHash2),
+		uintptr(endSeedHash1),
+	)
+	if err != nil && !errors.Is(err, syscall.Errno(0)) {
+		return 0, fmt.Errorf("get_seed failed: %w", err)
+	}
+	if uint32(ret) == 0 {
+		return 0, errNoSeed
+	}
+	return uint32(ret), nil
+}
+
+// defaultSeedProvider derives seeds in Go and calls rustdecrypt.dll, when it
+// is installed, only for hashes Go finds no seed for. With verify set every
+// seed is also checked against the DLL.
+func defaultSeedProvider(verify bool) SeedProvider {
+	var provider SeedProvider = GoSeedProvider{}
+	if verify {
+		provider = verifiedSeedProvider{provider: provider, reference: DLLSeedProvider{}}
+	}
+	return SeedProviders{provider, DLLSeedProvider{}}
+}
@@ -0,0 +1,17 @@
+// memory/mapseed_windows_test.go
+
+package memory
+
+import "testing"
+
+func TestDLLSeedProvider(t *testing.T) {
+	if err := procGetSeed.Find(); err != nil {
+		t.Skipf("rustdecrypt.dll isn't installed: %v", err)
+	}
+	for _, v := range seedVectors {
+		seed, err := DLLSeedProvider{}.MapSeed(v.initSeedHash1, v.initSeedHash2, v.endSeedHash1)
+		if err != nil || seed != v.mapSeed {
+			t.Errorf("MapSeed(0x%08X, 0x%08X, 0x%08X) = %d, %v; want %d", v.initSeedHash1, v.initSeedHash2, v.endSeedHash1, seed, err, v.mapSeed)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"log"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// offsetCache maps a D2R build key to the offsets resolved for that build
+type offsetCache struct {
+	Builds map[string]map[string]uint64 `yaml:"builds"`
+}
+
+// loadOffsetCache reads the offset cache, returning an empty cache if it is missing or unreadable.
+func loadOffsetCache(filePath string) *offsetCache {
+	cache := &offsetCache{}
+	data, err := os.ReadFile(filePath)
+	if err == nil {
+		if err := yaml.Unmarshal(data, cache); err != nil {
+			log.Printf("Ignoring unreadable offset cache %s: %v", filePath, err)
+			cache = &offsetCache{}
+		}
+	}
+	if cache.Builds == nil {
+		cache.Builds = make(map[string]map[string]uint64)
+	}
+	return cache
+}
+
+func saveOffsetCache(filePath string, cache *offsetCache) error {
+	data, err := yaml.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
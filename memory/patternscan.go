@@ -1,107 +1,131 @@
 package memory
 
 import (
+	"GalyMap/config"
 	"GalyMap/globals"
 	"GalyMap/utils"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"log"
 )
 
-func PatternScan(d2r *utils.ClassMemory) error {
+var (
+	// SignaturesFile holds the signature table used to resolve offsets
+	SignaturesFile = "config/signatures.yaml"
+	// OffsetCacheFile holds offsets resolved for previously seen D2R builds
+	OffsetCacheFile = "config/offsets_cache.yaml"
 
-	// unit table
-	unitPattern := "48 03 C7 49 8B 8C C6"
-	unitPatternAddress, err := d2r.ModulePatternScan("D2R.exe", unitPattern)
-	if err != nil {
-		return err
-	}
-	unitTable, err := d2r.Read(unitPatternAddress+7, "Int")
+	// requiredOffsets are the offsets the memory readers depend on
+	requiredOffsets = []string{"unitTable", "uiOffset", "expOffset", "gameDataOffset", "menuOffset", "hoverOffset", "rosterOffset"}
+)
+
+// PatternScan resolves every offset in the signature table and stores it in globals.Offsets.
+// Offsets resolved for the same D2R build and signature table are loaded from the cache
+// instead of scanning the module again. Only with persist are the signature table and the
+// offsets of a new build written to their files, so that replays leave config/ untouched.
+func PatternScan(d2r utils.ProcessReader, persist bool) error {
+	signatures, table, err := config.LoadSignatures(SignaturesFile, persist)
 	if err != nil {
 		return err
 	}
-	globals.Offsets.M["unitTable"] = uintptr(unitTable.(int32)) // Convert int32 to uintptr
-	// log.Printf("Scanned and found unitTable offset: 0x%X", globals.Offsets.M["unitTable"])
 
-	// ui
-	uiPattern := "40 84 ed 0f 94 05"
-	uiPatternAddress, err := d2r.ModulePatternScan("D2R.exe", uiPattern)
+	moduleSize, err := utils.ModuleImageSize(d2r)
 	if err != nil {
 		return err
 	}
-	uiOffsetBuffer, err := d2r.Read(uiPatternAddress+6, "Int")
+	timestamp, err := utils.ModuleTimestamp(d2r)
 	if err != nil {
 		return err
 	}
-	uiOffset := ((uiPatternAddress - d2r.BaseAddress) + 10 + uintptr(uiOffsetBuffer.(int32)))
-	globals.Offsets.M["uiOffset"] = uiOffset
-	// log.Printf("Scanned and found UI offset: 0x%X", uiOffset)
+	buildKey := fmt.Sprintf("%08X-%08X-%X", timestamp, moduleSize, sha1.Sum(table))
 
-	// expansion
-	expansionPattern := "48 8B 05 ?? ?? ?? ?? 48 8B D9 F3 0F 10 50 ??"
-	expPatternAddress, err := d2r.ModulePatternScan("D2R.exe", expansionPattern)
-	if err != nil {
-		return err
+	cache := loadOffsetCache(OffsetCacheFile)
+	if offsets, ok := cache.Builds[buildKey]; ok && hasOffsets(offsets, signatures) {
+		for name, value := range offsets {
+			globals.SetOffset(name, uintptr(value))
+		}
+		log.Printf("Loaded %d cached offsets for D2R build %s", len(offsets), buildKey)
+		return checkRequiredOffsets()
 	}
-	expOffsetBuffer, err := d2r.Read(expPatternAddress+3, "Int")
-	if err != nil {
-		return err
-	}
-	expOffset := ((expPatternAddress - d2r.BaseAddress) + 7 + uintptr(expOffsetBuffer.(int32)))
-	globals.Offsets.M["expOffset"] = expOffset
-	// log.Printf("Scanned and found expansion offset: 0x%X", expOffset)
 
-	// game data (IP and name)
-	gameDataPattern := "44 88 25 ?? ?? ?? ?? 66 44 89 25 ?? ?? ?? ??"
-	gameDataPatternAddress, err := d2r.ModulePatternScan("D2R.exe", gameDataPattern)
+	log.Printf("No cached offsets for D2R build %s, scanning module", buildKey)
+	module, err := d2r.ReadRaw(d2r.BaseAddress(), moduleSize)
 	if err != nil {
 		return err
 	}
-	gameDataOffsetBuffer, err := d2r.Read(gameDataPatternAddress+0x3, "Int")
-	if err != nil {
-		return err
+
+	patterns := make([]utils.Pattern, len(signatures))
+	for i, sig := range signatures {
+		patterns[i], err = utils.ParsePattern(sig.Pattern)
+		if err != nil {
+			return fmt.Errorf("signature %s: %v", sig.Name, err)
+		}
 	}
-	gameDataOffset := ((gameDataPatternAddress - d2r.BaseAddress) - 0x121 + uintptr(gameDataOffsetBuffer.(int32)))
-	globals.Offsets.M["gameDataOffset"] = gameDataOffset
-	// log.Printf("Scanned and found game data offset: 0x%X", gameDataOffset)
+	allMatches := utils.NewScanner(patterns...).ScanAll(module)
 
-	// menu visibility
-	menuPattern := "8B 05 ?? ?? ?? ?? 89 44 24 20 74 07"
-	menuPatternAddress, err := d2r.ModulePatternScan("D2R.exe", menuPattern)
-	if err != nil {
-		return err
+	offsets := make(map[string]uint64, len(signatures))
+	for i, sig := range signatures {
+		offset, err := resolveSignature(module, sig, allMatches[i])
+		if err != nil {
+			return err
+		}
+		globals.SetOffset(sig.Name, offset)
+		offsets[sig.Name] = uint64(offset)
+		// log.Printf("Scanned and found %s offset: 0x%X", sig.Name, offset)
 	}
-	menuOffsetBuffer, err := d2r.Read(menuPatternAddress+2, "Int")
-	if err != nil {
+	if err := checkRequiredOffsets(); err != nil {
 		return err
 	}
-	menuOffset := ((menuPatternAddress - d2r.BaseAddress) + 6 + uintptr(menuOffsetBuffer.(int32)))
-	globals.Offsets.M["menuOffset"] = menuOffset
-	// log.Printf("Scanned and found menu offset: 0x%X", menuOffset)
 
-	// last hover object
-	hoverPattern := "C6 84 C2 ?? ?? ?? ?? ?? 48 8B 74 24 ??"
-	hoverPatternAddress, err := d2r.ModulePatternScan("D2R.exe", hoverPattern)
-	if err != nil {
-		return err
+	if !persist {
+		return nil
 	}
-	hoverOffset, err := d2r.Read(hoverPatternAddress+3, "Int")
-	if err != nil {
-		return err
+	cache.Builds[buildKey] = offsets
+	err = saveOffsetCache(OffsetCacheFile, cache)
+	utils.IfError(err, "Failed to save offset cache")
+	return nil
+}
+
+// resolveSignature checks the matches of sig in the module image and applies its displacement rule.
+func resolveSignature(module []byte, sig config.Signature, matches []int) (uintptr, error) {
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("%w: %s pattern not found", ErrSignatureMissing, sig.Name)
+	}
+	if sig.Count > 0 && len(matches) != sig.Count {
+		// The pattern no longer tells the offset apart, whichever match is taken may be wrong
+		return 0, fmt.Errorf("%w: %s pattern matched %d times, expected %d", ErrSignatureMissing, sig.Name, len(matches), sig.Count)
 	}
-	globals.Offsets.M["hoverOffset"] = uintptr(hoverOffset.(int32)) - 1
-	// log.Printf("Scanned and found hover offset: 0x%X", globals.Offsets.M["hoverOffset"])
 
-	// roster
-	rosterPattern := "02 45 33 D2 4D 8B"
-	rosterPatternAddress, err := d2r.ModulePatternScan("D2R.exe", rosterPattern)
-	if err != nil {
-		return err
+	match := matches[0]
+	dispAt := match + sig.Read
+	if dispAt < 0 || dispAt+4 > len(module) {
+		return 0, fmt.Errorf("signature %s: displacement at %d is outside the module", sig.Name, sig.Read)
 	}
-	rosterOffsetBuffer, err := d2r.Read(rosterPatternAddress-3, "Int")
-	if err != nil {
-		return err
+	disp := int64(int32(binary.LittleEndian.Uint32(module[dispAt:])))
+
+	offset := disp + sig.Adjust
+	if sig.Relative {
+		offset += int64(match)
+	}
+	return uintptr(offset), nil
+}
+
+// hasOffsets reports whether a cache entry covers every signature in the table.
+func hasOffsets(offsets map[string]uint64, signatures []config.Signature) bool {
+	for _, sig := range signatures {
+		if _, ok := offsets[sig.Name]; !ok {
+			return false
+		}
 	}
-	rosterOffset := ((rosterPatternAddress - d2r.BaseAddress) + 1 + uintptr(rosterOffsetBuffer.(int32)))
-	globals.Offsets.M["rosterOffset"] = rosterOffset
-	// log.Printf("Scanned and found roster offset: 0x%X", rosterOffset)
+	return true
+}
 
+func checkRequiredOffsets() error {
+	for _, name := range requiredOffsets {
+		if _, ok := globals.GetOffset(name); !ok {
+			return fmt.Errorf("%w: signature table has no entry for %s", ErrSignatureMissing, name)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,83 @@
+// memory/patternscan_test.go
+
+package memory
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"GalyMap/config"
+	"GalyMap/utils"
+)
+
+// testModule returns a module image with a minimal PE header, so that the build key can be read.
+func testModule(size int) []byte {
+	module := make([]byte, size)
+	copy(module, "MZ")
+	binary.LittleEndian.PutUint32(module[0x3C:], 0x80)
+	copy(module[0x80:], "PE\x00\x00")
+	binary.LittleEndian.PutUint32(module[0x88:], 0x5F3759DF)
+	binary.LittleEndian.PutUint32(module[0x80+4+20+0x38:], uint32(size))
+	return module
+}
+
+func TestResolveSignature(t *testing.T) {
+	module := testModule(0x1000)
+	copy(module[0x400:], []byte{0x48, 0x8D, 0x05, 0x10, 0x00, 0x00, 0x00})
+
+	tests := []struct {
+		name    string
+		sig     config.Signature
+		matches []int
+		want    uintptr
+		wantErr bool
+	}{
+		{"absolute", config.Signature{Name: "a", Count: 1, Read: 3, Adjust: 8}, []int{0x400}, 0x18, false},
+		{"relative", config.Signature{Name: "r", Count: 1, Read: 3, Relative: true, Adjust: 7}, []int{0x400}, 0x400 + 7 + 0x10, false},
+		{"unchecked count", config.Signature{Name: "u", Read: 3}, []int{0x400, 0x800}, 0x10, false},
+		{"not found", config.Signature{Name: "n", Count: 1, Read: 3}, nil, 0, true},
+		{"ambiguous", config.Signature{Name: "m", Count: 1, Read: 3}, []int{0x400, 0x800}, 0, true},
+		{"too few", config.Signature{Name: "f", Count: 2, Read: 3}, []int{0x400}, 0, true},
+		{"displacement outside", config.Signature{Name: "o", Count: 1, Read: 3}, []int{0xFFE}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveSignature(module, tt.sig, tt.matches)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveSignature = 0x%X, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolveSignature = 0x%X, %v; want 0x%X", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternScanAmbiguousSignature(t *testing.T) {
+	dir := t.TempDir()
+	savedSignatures, savedCache := SignaturesFile, OffsetCacheFile
+	SignaturesFile, OffsetCacheFile = filepath.Join(dir, "signatures.yaml"), filepath.Join(dir, "offsets_cache.yaml")
+	defer func() { SignaturesFile, OffsetCacheFile = savedSignatures, savedCache }()
+
+	table := "signatures:\n  - name: unitTable\n    pattern: \"DE AD BE EF\"\n    count: 1\n    read: 4\n"
+	if err := os.WriteFile(SignaturesFile, []byte(table), 0644); err != nil {
+		t.Fatal(err)
+	}
+	module := testModule(0x1000)
+	copy(module[0x400:], []byte{0xDE, 0xAD, 0xBE, 0xEF})
+	copy(module[0x800:], []byte{0xDE, 0xAD, 0xBE, 0xEF})
+	reader := utils.NewSnapshotReader(&utils.Snapshot{BaseAddress: 0x140000000, Module: module})
+
+	if err := PatternScan(reader, true); !errors.Is(err, ErrSignatureMissing) {
+		t.Errorf("PatternScan = %v, want ErrSignatureMissing", err)
+	}
+	if _, err := os.Stat(OffsetCacheFile); !os.IsNotExist(err) {
+		t.Errorf("an ambiguous signature was written to the offset cache")
+	}
+}
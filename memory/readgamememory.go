@@ -4,8 +4,8 @@ package memory
 import (
 	"GalyMap/globals"
 	"GalyMap/utils"
-	"log"
-	"syscall"
+	"encoding/binary"
+	"fmt"
 )
 
 var (
@@ -15,47 +15,65 @@ var (
 	// xorkey              uint32
 	playerLevel       uint32
 	experience        uint32
-	modRustDecrypt    = syscall.NewLazyDLL("rustdecrypt.dll")
-	procGetSeed       = modRustDecrypt.NewProc("get_seed")
-	partyList         []globals.Player
 	lastHoveredType   uint32
 	lastHoveredUnitId uint32
 )
 
-func ReadGameMemory(d2r *utils.ClassMemory, settings map[string]bool) {
-	playerPointer := ScanForPlayer(d2r, globals.Offsets.M["unitTable"])
+// ReadGameMemory reads one tick of game state and publishes it. It returns an
+// error wrapping ErrNotInGame, ErrStalePointer, ErrReadFault or ErrSignatureMissing
+// when the player's state can't be read, in which case nothing is published.
+// Failures of the optional readers (mobs, items, ...) are logged and leave
+// their part of the state empty.
+func ReadGameMemory(d2r utils.ProcessReader, settings map[string]bool) error {
+	unitTable, ok := globals.GetOffset("unitTable")
+	if !ok {
+		return fmt.Errorf("%w: unitTable", ErrSignatureMissing)
+	}
+	playerPointer := ScanForPlayer(d2r, unitTable)
+	if playerPointer == 0 {
+		return ErrNotInGame
+	}
 
 	playerUnit := playerPointer
-	unitId, err := utils.ReadAndAssert[uint32](d2r, playerUnit+0x08, "UInt")
-	utils.IfError(err, "Failed to read unitId")
-
-	pPath, err := utils.ReadAndAssert[int64](d2r, playerUnit+0x38, "Int64")
-	utils.IfError(err, "Failed to read pPath")
-	pRoom1Address, err := utils.ReadAndAssert[int64](d2r, uintptr(pPath+0x20), "Int64")
-	utils.IfError(err, "Failed to read pRoom1Address")
-	pRoom2Address, err := utils.ReadAndAssert[int64](d2r, uintptr(pRoom1Address+0x18), "Int64")
-	utils.IfError(err, "Failed to read pRoom2Address")
-	pLevelAddress, err := utils.ReadAndAssert[int64](d2r, uintptr(pRoom2Address+0x90), "Int64")
-	utils.IfError(err, "Failed to read pLevelAddress")
-	levelNo, err := utils.ReadAndAssert[uint32](d2r, uintptr(pLevelAddress+0x1F8), "UInt")
-	utils.IfError(err, "Failed to read levelNo")
-
-	playerNameAddress, err := utils.ReadAndAssert[int64](d2r, playerUnit+0x10, "Int64")
-	utils.IfError(err, "Failed to read playerNameAddress")
-	playerName, err := d2r.ReadString(uintptr(playerNameAddress), 0, "utf-8")
+	unit, err := utils.ReadStruct[UnitAny](d2r, playerUnit)
+	if err != nil {
+		return fmt.Errorf("player unit: %w", err)
+	}
+	unitId := unit.UnitId
+
+	path, err := utils.ReadStruct[Path](d2r, uintptr(unit.Path))
+	if err != nil {
+		return fmt.Errorf("player path: %w", err)
+	}
+	room1, err := utils.ReadStruct[Room1](d2r, uintptr(path.Room1))
+	if err != nil {
+		return fmt.Errorf("player room: %w", err)
+	}
+	room2, err := utils.ReadStruct[Room2](d2r, uintptr(room1.Room2))
+	if err != nil {
+		return fmt.Errorf("player room: %w", err)
+	}
+	level, err := utils.ReadStruct[Level](d2r, uintptr(room2.Level))
+	if err != nil {
+		return fmt.Errorf("player level: %w", err)
+	}
+	levelNo := level.LevelNo
+
+	playerName, err := d2r.ReadString(uintptr(unit.UnitData), 0, "utf-8")
 	utils.IfError(err, "Failed to read playerName")
 
-	actAddress, err := utils.ReadAndAssert[int64](d2r, playerUnit+0x20, "Int64")
-	utils.IfError(err, "Failed to read actAddress")
-	actMiscAddress, err := utils.ReadAndAssert[int64](d2r, uintptr(actAddress+0x78), "Int64")
-	utils.IfError(err, "Failed to read actMiscAddress")
+	act, err := utils.ReadStruct[Act](d2r, uintptr(unit.Act))
+	if err != nil {
+		return fmt.Errorf("player act: %w", err)
+	}
+	actMisc, err := utils.ReadStruct[ActMisc](d2r, uintptr(act.ActMisc))
+	if err != nil {
+		return fmt.Errorf("player act: %w", err)
+	}
 
-	dwInitSeedHash1, err := utils.ReadAndAssert[uint32](d2r, uintptr(actMiscAddress+0x840), "UInt")
-	utils.IfError(err, "Failed to read dwInitSeedHash1")
-	dwInitSeedHash2, err := utils.ReadAndAssert[uint32](d2r, uintptr(actMiscAddress+0x844), "UInt")
-	utils.IfError(err, "Failed to read dwInitSeedHash2")
-	dwEndSeedHash1, err := utils.ReadAndAssert[uint32](d2r, uintptr(actMiscAddress+0x868), "UInt")
-	utils.IfError(err, "Failed to read dwEndSeedHash1")
+	dwInitSeedHash1 := actMisc.InitSeedHash1
+	dwInitSeedHash2 := actMisc.InitSeedHash2
+	dwEndSeedHash1 := actMisc.EndSeedHash1
 
 	if dwInitSeedHash1 != lastdwInitSeedHash1 || dwInitSeedHash2 != lastdwInitSeedHash2 || globals.MapSeed == 0 {
 		globals.MapSeed = calculateMapSeed(dwInitSeedHash1, dwInitSeedHash2, dwEndSeedHash1)
@@ -63,157 +81,117 @@ func ReadGameMemory(d2r *utils.ClassMemory, settings map[string]bool) {
 		lastdwInitSeedHash2 = dwInitSeedHash2
 	}
 
-	aActUnk2, err := utils.ReadAndAssert[int64](d2r, uintptr(actAddress+0x78), "Int64")
-	utils.IfError(err, "Failed to read aActUnk2")
-	difficulty, err := utils.ReadAndAssert[uint16](d2r, uintptr(aActUnk2+0x830), "UShort")
-	utils.IfError(err, "Failed to read difficulty")
+	difficulty := actMisc.Difficulty
 
 	if globals.Ticktock%6 == 0 {
-		pStatsListEx, err := utils.ReadAndAssert[int64](d2r, playerUnit+0x88, "Int64")
-		utils.IfError(err, "Failed to read pStatsListEx")
-		statPtr, err := utils.ReadAndAssert[int64](d2r, uintptr(pStatsListEx+0x30), "Int64")
-		utils.IfError(err, "Failed to read statPtr")
-		statCount, err := utils.ReadAndAssert[int64](d2r, uintptr(pStatsListEx+0x38), "Int64")
-		utils.IfError(err, "Failed to read statCount")
-		buffer, err := d2r.ReadRaw(uintptr(statPtr+0x2), uint32(statCount*8))
-		utils.IfError(err, "Failed to read raw stats")
-
-		for i := 0; i < int(statCount); i++ {
-			offset := i * 8
-			statEnum, err := utils.ReadBufferAndAssert[uint16](buffer, offset, "UShort")
-			utils.IfError(err, "Failed to get statEnum")
-			statValue, err := utils.ReadBufferAndAssert[uint32](buffer, offset+0x2, "UInt")
-			utils.IfError(err, "Failed to get statValue")
-			if statEnum == 12 {
-				playerLevel = statValue
-			}
-			if statEnum == 13 {
-				experience = statValue
+		statList, err := utils.ReadStruct[StatListEx](d2r, uintptr(unit.StatListEx))
+		if err == nil && statList.StatCount > maxStatCount {
+			err = fmt.Errorf("%w: player stat count %d", ErrStalePointer, statList.StatCount)
+		}
+		if err == nil {
+			var stats []Stat
+			stats, err = utils.ReadStructs[Stat](d2r, uintptr(statList.Stats), int(statList.StatCount), statSize)
+			for _, stat := range stats {
+				if stat.Id == 12 {
+					playerLevel = stat.Value
+				}
+				if stat.Id == 13 {
+					experience = stat.Value
+				}
 			}
 		}
+		utils.IfError(err, "Failed to read player stats")
 	}
 
-	hoverAddress := d2r.BaseAddress + globals.Offsets.M["hoverOffset"]
+	hoverAddress := d2r.BaseAddress() + globals.Offsets.M["hoverOffset"]
 	hoverBuffer, err := d2r.ReadRaw(uintptr(hoverAddress), 12)
-	utils.IfError(err, "Failed to read hoverBuffer")
-	isHovered, err := utils.ReadBufferAndAssert[uint8](hoverBuffer, 0, "UChar")
-	utils.IfError(err, "Failed to get isHovered")
-	if isHovered != 0 {
-		lastHoveredType, err = utils.ReadBufferAndAssert[uint32](hoverBuffer, 0x04, "UInt")
-		utils.IfError(err, "Failed to get lastHoveredType")
-		lastHoveredUnitId, err = utils.ReadBufferAndAssert[uint32](hoverBuffer, 0x08, "UInt")
-		utils.IfError(err, "Failed to get lastHoveredUnitId")
+	if err == nil && len(hoverBuffer) == 12 {
+		if hoverBuffer[0] != 0 {
+			lastHoveredType = binary.LittleEndian.Uint32(hoverBuffer[0x04:])
+			lastHoveredUnitId = binary.LittleEndian.Uint32(hoverBuffer[0x08:])
+		}
+	} else {
+		utils.IfError(err, "Failed to read hoverBuffer")
 	}
 
+	// The readers that don't run every tick, or are turned off, keep what the previous tick read
+	previous := globals.CurrentGameState()
+	partyList, otherPlayers := previous.PartyList, previous.OtherPlayers
+	mobs, hoveredMob := previous.Mobs, previous.HoveredMob
+	items, gameObjects := previous.Items, previous.Objects
+
 	if globals.Ticktock%3 == 0 {
-		ReadParty(d2r, unitId)
+		partyList, err = ReadParty(d2r, unitId)
+		utils.IfError(err, "Failed to read party")
 	}
 
 	if settings["showOtherPlayers"] {
-		ReadOtherPlayers(d2r, globals.Offsets.M["unitTable"], int(levelNo), partyList)
+		otherPlayers, err = ReadOtherPlayers(d2r, unitTable, int(levelNo), partyList)
+		utils.IfError(err, "Failed to read other players")
 	}
 
 	if settings["showNormalMobs"] || settings["showUniqueMobs"] || settings["showBosses"] || settings["showDeadMobs"] {
 		if lastHoveredType != 0 {
-			ReadMobs(d2r, globals.Offsets.M["unitTable"], lastHoveredUnitId)
+			mobs, hoveredMob, err = ReadMobs(d2r, unitTable, lastHoveredUnitId)
 		} else {
-			ReadMobs(d2r, globals.Offsets.M["unitTable"], 0)
+			mobs, hoveredMob, err = ReadMobs(d2r, unitTable, 0)
 		}
+		utils.IfError(err, "Failed to read mobs")
 	}
 
-	missiles := []interface{}{}
+	var playerMissiles, enemyMissiles []globals.Missile
 	if settings["showPlayerMissiles"] {
-		playerMissiles, err := ReadMissiles(d2r, int(globals.Offsets.M["unitTable"]+(6*1024)))
+		playerMissiles, err = ReadMissiles(d2r, int(unitTable+(6*1024)))
 		utils.IfError(err, "Failed to read playerMissiles")
-		missiles = append(missiles, playerMissiles)
 	}
 
 	if settings["showEnemyMissiles"] {
-		enemyMissiles, err := ReadMissiles(d2r, int(globals.Offsets.M["unitTable"]))
+		enemyMissiles, err = ReadMissiles(d2r, int(unitTable))
 		utils.IfError(err, "Failed to read enemyMissiles")
-		missiles = append(missiles, enemyMissiles)
 	}
 
 	if settings["enableItemFilter"] && globals.Ticktock%3 == 0 {
-		ReadItems(d2r, globals.Offsets.M["unitTable"], globals.ItemAlertList)
+		items, err = ReadItems(d2r, unitTable, globals.ItemAlertList)
+		utils.IfError(err, "Failed to read items")
 	}
 
 	if settings["showShrines"] || settings["showPortals"] || settings["showChests"] && globals.Ticktock%6 == 0 {
 		if lastHoveredType == 2 {
-			ReadObjects(d2r, int(globals.Offsets.M["unitTable"]), lastHoveredUnitId, int(levelNo))
+			gameObjects, err = ReadObjects(d2r, int(unitTable), lastHoveredUnitId, int(levelNo))
 		} else {
-			ReadObjects(d2r, int(globals.Offsets.M["unitTable"]), 0, int(levelNo))
+			gameObjects, err = ReadObjects(d2r, int(unitTable), 0, int(levelNo))
 		}
+		utils.IfError(err, "Failed to read objects")
 	}
 
 	menuShown, err := ReadUI(d2r)
 	utils.IfError(err, "Failed to read UI")
 
-	pathAddress, err := utils.ReadAndAssert[int64](d2r, playerUnit+0x38, "Int64")
-	utils.IfError(err, "Failed to read pathAddress")
-
-	pathBuffer, err := d2r.ReadRaw(uintptr(pathAddress), 8)
-	utils.IfError(err, "Failed to read path buffer")
-
-	xPosOffset, err := utils.ReadBufferAndAssert[uint16](pathBuffer, 0x00, "UShort")
-	utils.IfError(err, "Failed to read xPosOffset")
-	xPosint, err := utils.ReadBufferAndAssert[uint16](pathBuffer, 0x02, "UShort")
-	utils.IfError(err, "Failed to read xPos")
-	yPosOffset, err := utils.ReadBufferAndAssert[uint16](pathBuffer, 0x04, "UShort")
-	utils.IfError(err, "Failed to read yPosOffset")
-	yPosint, err := utils.ReadBufferAndAssert[uint16](pathBuffer, 0x06, "UShort")
-	utils.IfError(err, "Failed to read yPos")
-	xPos := float64(xPosint) + (float64(xPosOffset) / 65536.0)
-	yPos := float64(yPosint) + (float64(yPosOffset) / 65536.0)
+	xPos, yPos := path.Position()
 
 	if xPos == 0 {
-		log.Printf("Did not find player position at player offset %v", globals.Offsets.M["unitTable"])
-	}
-
-	globals.GameDataMutex.Lock()
-	defer globals.GameDataMutex.Unlock()
-
-	globals.GameMemoryData = make(map[string]interface{})
-	globals.GameMemoryData["playerPointer"] = playerPointer
-	globals.GameMemoryData["pathAddress"] = pathAddress
-	// globals.GameMemoryData["gameName"] = gameName
-	globals.GameMemoryData["mapSeed"] = globals.MapSeed
-	globals.GameMemoryData["difficulty"] = difficulty
-	globals.GameMemoryData["levelNo"] = levelNo
-	globals.GameMemoryData["xPos"] = xPos
-	globals.GameMemoryData["yPos"] = yPos
-	globals.GameMemoryData["mobs"] = globals.Mobs
-	globals.GameMemoryData["missiles"] = missiles
-	globals.GameMemoryData["otherPlayers"] = globals.OtherPlayers
-	globals.GameMemoryData["items"] = globals.Items
-	globals.GameMemoryData["objects"] = globals.GameObjects
-	globals.GameMemoryData["playerName"] = playerName
-	globals.GameMemoryData["experience"] = experience
-	globals.GameMemoryData["playerLevel"] = playerLevel
-	globals.GameMemoryData["menuShown"] = menuShown
-	globals.GameMemoryData["hoveredMob"] = globals.HoveredMob
-	globals.GameMemoryData["partyList"] = partyList
-	globals.GameMemoryData["unitId"] = unitId
-}
-
-func calculateMapSeed(InitSeedHash1, InitSeedHash2, EndSeedHash1 uint32) uint32 {
-	// log.Printf("Calculating new map seed from %v %v %v", InitSeedHash1, InitSeedHash2, EndSeedHash1)
-
-	// Call the DLL function
-	ret, _, err := procGetSeed.Call(
-		uintptr(InitSeedHash1),
-		uintptr(InitSeedHash2),
-		uintptr(EndSeedHash1),
-	)
-	if err != nil && err.Error() != "The operation completed successfully." {
-		log.Printf("Failed to call get_seed: %v", err)
-		return 0
+		return fmt.Errorf("%w: player position is zero", ErrStalePointer)
 	}
 
-	mapSeed := uint32(ret)
-	// log.Printf("Found mapSeed '%v'", mapSeed)
-	if mapSeed == 0 {
-		log.Printf("ERROR: YOU HAVE AN ERROR DECRYPTING THE MAP SEED, YOUR MAPS WILL EITHER NOT APPEAR OR NOT LINE UP")
-	}
-	return mapSeed
+	globals.PublishGameState(&globals.GameState{
+		PlayerPointer:  playerPointer,
+		UnitId:         unitId,
+		PlayerName:     playerName,
+		PlayerLevel:    playerLevel,
+		Experience:     experience,
+		Pos:            globals.UnitPosition{X: xPos, Y: yPos},
+		LevelNo:        levelNo,
+		Difficulty:     difficulty,
+		MapSeed:        globals.MapSeed,
+		MenuShown:      menuShown,
+		Mobs:           mobs,
+		HoveredMob:     hoveredMob,
+		PlayerMissiles: playerMissiles,
+		EnemyMissiles:  enemyMissiles,
+		OtherPlayers:   otherPlayers,
+		PartyList:      partyList,
+		Items:          items,
+		Objects:        gameObjects,
+	})
+	return nil
 }
@@ -1,86 +1,33 @@
 package memory
 
 import (
+	"GalyMap/globals"
 	"GalyMap/utils"
-	"encoding/binary"
 )
 
-func ReadMissiles(d2r *utils.ClassMemory, startingOffset int) ([]map[string]interface{}, error) {
-	var array []map[string]interface{}
-	tableOffset := startingOffset + (3 * 1024)
-	baseAddress := d2r.BaseAddress + uintptr(tableOffset)
-	unitTableBuffer, err := d2r.ReadRaw(baseAddress, 128*8)
-	if err != nil {
-		return nil, err
-	}
-
-	for i := 0; i < 128; i++ {
-		offset := 8 * i
-		arrayUnit := binary.LittleEndian.Uint64(unitTableBuffer[offset:])
-
-		for arrayUnit > 0 {
-			unitBuffer, err := d2r.ReadRaw(uintptr(arrayUnit), 0x150+8)
-			if err != nil {
-				return nil, err
-			}
-
-			txtFileNo, err := utils.ReadBufferAndAssert[uint32](unitBuffer, 0x04, "UInt")
-			if err != nil {
-				return nil, err
-			}
-			missileCategory := getMissileCategory(int(txtFileNo))
-			if missileCategory != "Unknown" {
-				pPathPtr, err := utils.ReadBufferAndAssert[uint64](unitBuffer, 0x38, "UInt64")
-				if err != nil {
-					return nil, err
-				}
-
-				pPathBuffer, err := d2r.ReadRaw(uintptr(pPathPtr), 16) // Assuming pPath points to a structure of 16 bytes
-				if err != nil {
-					return nil, err
-				}
-
-				mode, err := utils.ReadBufferAndAssert[uint32](unitBuffer, 0x0c, "UInt")
-				if err != nil {
-					return nil, err
-				}
-				unitx, err := utils.ReadBufferAndAssert[uint16](pPathBuffer, 0x02, "UShort")
-				if err != nil {
-					return nil, err
-				}
-				unity, err := utils.ReadBufferAndAssert[uint16](pPathBuffer, 0x06, "UShort")
-				if err != nil {
-					return nil, err
-				}
-				xPosOffset, err := utils.ReadBufferAndAssert[uint16](pPathBuffer, 0x00, "UShort")
-				if err != nil {
-					return nil, err
-				}
-				yPosOffset, err := utils.ReadBufferAndAssert[uint16](pPathBuffer, 0x04, "UShort")
-				if err != nil {
-					return nil, err
-				}
-				xPosOffset = uint16(uint32(xPosOffset) / 65536)
-				yPosOffset = uint16(uint32(yPosOffset) / 65536)
-				unitx += xPosOffset
-				unity += yPosOffset
-
-				unit := map[string]interface{}{
-					"txtFileNo": txtFileNo,
-					"x":         unitx,
-					"y":         unity,
-					"mode":      mode,
-					"UnitType":  missileCategory,
-				}
-				array = append(array, unit)
-			}
-			arrayUnit, err = utils.ReadBufferAndAssert[uint64](unitBuffer, 0x150, "UInt64")
-			if err != nil {
-				return nil, err
-			}
+func ReadMissiles(d2r utils.ProcessReader, startingOffset int) ([]globals.Missile, error) {
+	var array []globals.Missile
+	err := WalkUnits(d2r, uintptr(startingOffset), unitMissile, func(_ uintptr, unit UnitAny) bool {
+		missileCategory := getMissileCategory(int(unit.TxtFileNo))
+		if missileCategory == "Unknown" {
+			return true
 		}
-	}
-	return array, nil
+		// Missiles that are freed while being read are skipped
+		path, err := utils.ReadStruct[Path](d2r, uintptr(unit.Path))
+		if err != nil {
+			return true
+		}
+		x, y := path.Position()
+		missile := globals.Missile{
+			TxtFileNo: unit.TxtFileNo,
+			Mode:      unit.Mode,
+			Pos:       globals.UnitPosition{X: x, Y: y},
+			Category:  missileCategory,
+		}
+		array = append(array, missile)
+		return true
+	})
+	return array, err
 }
 
 func GetMissileName(txtFileNo int) string {
@@ -3,178 +3,167 @@ package memory
 import (
 	"GalyMap/globals"
 	"GalyMap/utils"
-	"encoding/binary"
 	// "log"
 )
 
-// ReadMobs reads mobs from the game process and updates the global mobs list and hoveredMob
-func ReadMobs(d2r *utils.ClassMemory, startingOffset uintptr, currentHoveringUnitId uint32) {
+// ReadMobs reads mobs from the game process, along with the mob under the cursor
+func ReadMobs(d2r utils.ProcessReader, startingOffset uintptr, currentHoveringUnitId uint32) ([]globals.Mob, globals.Mob, error) {
 
 	// log.Printf("Reading mobs")
-	globals.Mobs = []globals.Mob{}     // Initialize the global mobs list
-	globals.HoveredMob = globals.Mob{} // Initialize the global hoveredMob
+	mobs := []globals.Mob{}
+	var hoveredMob globals.Mob
 
-	baseAddress := d2r.BaseAddress + startingOffset + 1024
-	unitTableBuffer, err := d2r.ReadRaw(baseAddress, 128*8)
-	utils.IfError(err, "Failed to read unit table buffer")
+	// Collect the units first so that the per-mob reads below can be batched
+	mobUnits, walkErr := CollectUnits(d2r, startingOffset, unitMonster)
+	planMobReads(d2r, mobUnits)
 
-	for i := 0; i < 128; i++ {
-		offset := i * 8
-		mobUnit := binary.LittleEndian.Uint64(unitTableBuffer[offset : offset+8])
-
-		// log.Printf("mobUnit: %d", mobUnit)
-
-		for mobUnit > 0 {
-			// Read the mob structure data
-			mobStructData, err := d2r.ReadRaw(uintptr(mobUnit), 144)
-			utils.IfError(err, "Failed to read mob structure data")
-
-			mobType, err := utils.ReadBufferAndAssert[uint32](mobStructData, 0x00, "UInt")
-			utils.IfError(err, "Failed to read mobType")
-			txtFileNo, err := utils.ReadBufferAndAssert[uint32](mobStructData, 0x04, "UInt")
-			utils.IfError(err, "Failed to read txtFileNo")
+	for _, unit := range mobUnits {
+		txtFileNo := unit.TxtFileNo
+		if HideNPC(txtFileNo) {
+			continue
+		}
 
-			if !HideNPC(txtFileNo) {
-				unitId, err := utils.ReadBufferAndAssert[uint32](mobStructData, 0x08, "UInt")
-				utils.IfError(err, "Failed to read unitId")
-				mode, err := utils.ReadBufferAndAssert[uint32](mobStructData, 0x0C, "UInt")
-				utils.IfError(err, "Failed to read mode")
-				pUnitData := binary.LittleEndian.Uint64(mobStructData[0x10:0x18])
-				pPath := binary.LittleEndian.Uint64(mobStructData[0x38:0x40])
+		// Units that are freed while being read are skipped
+		monsterData, err := utils.ReadStruct[MonsterData](d2r, uintptr(unit.UnitData))
+		if err != nil {
+			continue
+		}
+		isCorpse := unit.IsCorpse == 1
 
-				dwOwnerId, err := utils.ReadAndAssert[uint32](d2r, uintptr(pUnitData+0x0C), "UInt")
-				utils.IfError(err, "Failed to read dwOwnerId")
-				isUnique, err := utils.ReadAndAssert[uint16](d2r, uintptr(pUnitData+0x18), "UShort")
-				utils.IfError(err, "Failed to read isUnique")
-				monsterFlag, err := utils.ReadAndAssert[uint8](d2r, uintptr(pUnitData+0x1A), "UChar")
-				utils.IfError(err, "Failed to read monsterFlag")
-				Corpseint, err := utils.ReadAndAssert[uint8](d2r, uintptr(pUnitData+0x1A6), "UInt")
-				utils.IfError(err, "Failed to read isCorpse")
-				isCorpse := Corpseint == 1
+		path, err := utils.ReadStruct[Path](d2r, uintptr(unit.Path))
+		if err != nil {
+			continue
+		}
+		monxFloat, monyFloat := path.Position()
 
-				// Read data from pPath
-				pathStructData, err := d2r.ReadRaw(uintptr(pPath), 16)
-				utils.IfError(err, "Failed to read path structure data")
+		isHovered := false
 
-				monx, err := utils.ReadBufferAndAssert[uint16](pathStructData, 0x02, "UShort")
-				utils.IfError(err, "Failed to read monx")
-				mony, err := utils.ReadBufferAndAssert[uint16](pathStructData, 0x06, "UShort")
-				utils.IfError(err, "Failed to read mony")
-				xPosOffset, err := utils.ReadBufferAndAssert[uint16](pathStructData, 0x00, "UShort")
-				utils.IfError(err, "Failed to read xPosOffset")
-				yPosOffset, err := utils.ReadBufferAndAssert[uint16](pathStructData, 0x04, "UShort")
-				utils.IfError(err, "Failed to read yPosOffset")
+		textTitle := getBossName(txtFileNo)
+		isBoss := textTitle != ""
 
-				// Calculate positions
-				xPosOffsetFloat := float64(xPosOffset) / 65536.0
-				yPosOffsetFloat := float64(yPosOffset) / 65536.0
-				monxFloat := float64(monx) + xPosOffsetFloat
-				monyFloat := float64(mony) + yPosOffsetFloat
+		superUniqueName := ""
+		if monsterData.MonsterFlag&monsterSuperUnique != 0 {
+			superUniqueName = GetSuperUniqueName(uint32(monsterData.SuperUniqueId))
+		}
 
-				isHovered := false
+		// Get immunities and other stats
+		statList, err := utils.ReadStruct[StatListEx](d2r, uintptr(unit.StatListEx))
+		if err != nil {
+			continue
+		}
 
-				textTitle := getBossName(txtFileNo)
-				isBoss := textTitle != ""
+		isPlayerMinion := false
+		playerMinion := getPlayerMinion(txtFileNo)
+		if playerMinion != "" {
+			isPlayerMinion = true
+		} else {
+			// Check if it's a revive
+			value, err := utils.ReadAndAssert[uint32](d2r, uintptr(unit.StatListEx+statListExOwnerFlags), "UInt")
+			if err == nil {
+				isPlayerMinion = (value & 31) == 1
+			}
+		}
 
-				// Get immunities and other stats
-				pStatsListEx, err := utils.ReadBufferAndAssert[int64](mobStructData, 0x88, "Int64")
-				utils.IfError(err, "Failed to read monsterFlag")
+		isTownNPC := isTownNPC(txtFileNo)
+		hp := uint32(0)
+		maxhp := uint32(0)
+		immunities := globals.Immunities{}
 
-				statPtr, err := utils.ReadAndAssert[int64](d2r, uintptr(pStatsListEx+0x30), "Int64")
-				utils.IfError(err, "Failed to read statPtr")
-				statCount, err := utils.ReadAndAssert[int64](d2r, uintptr(pStatsListEx+0x38), "Int64")
-				utils.IfError(err, "Failed to read statCount")
+		if !isPlayerMinion {
+			// Read stats
+			if statList.StatCount > maxStatCount {
+				continue
+			}
+			stats, err := utils.ReadStructs[Stat](d2r, uintptr(statList.Stats), int(statList.StatCount), statSize)
+			if err != nil {
+				continue
+			}
 
-				isPlayerMinion := false
-				playerMinion := getPlayerMinion(txtFileNo)
-				if playerMinion != "" {
-					isPlayerMinion = true
-				} else {
-					// Check if it's a revive
-					value, err := utils.ReadAndAssert[uint32](d2r, uintptr(pStatsListEx+0xAC8+0xC), "UInt")
-					if err == nil {
-						isPlayerMinion = (value & 31) == 1
-					}
+			for _, stat := range stats {
+				statValue := stat.Value
+				switch stat.Id {
+				case 36:
+					immunities.Physical = statValue
+				case 37:
+					immunities.Magic = statValue
+				case 39:
+					immunities.Fire = statValue
+				case 41:
+					immunities.Light = statValue
+				case 43:
+					immunities.Cold = statValue
+				case 45:
+					immunities.Poison = statValue
+				case 6:
+					hp = statValue >> 8
+				case 7:
+					maxhp = statValue >> 8
 				}
+			}
 
-				isTownNPC := isTownNPC(txtFileNo)
-				hp := uint32(0)
-				maxhp := uint32(0)
-				immunities := globals.Immunities{}
-
-				if !isPlayerMinion {
-					// Read stats
-					statBufferSize := uintptr(statCount * 8)
-					statsBuffer, err := d2r.ReadRaw(uintptr(statPtr)+0x2, uint32(statBufferSize))
-					utils.IfError(err, "Failed to read stats buffer")
-
-					for i := int64(0); i < statCount; i++ {
-						offset := i * 8
-						statEnum, err := utils.ReadBufferAndAssert[uint16](statsBuffer, int(offset), "UShort")
-						utils.IfError(err, "Failed to read statEnum")
-						statValue, err := utils.ReadBufferAndAssert[uint32](statsBuffer, int(offset+2), "UInt")
-						utils.IfError(err, "Failed to read statValue")
+			if currentHoveringUnitId != 0 && currentHoveringUnitId == unit.UnitId && isTownNPC == "" {
+				isHovered = true
+			}
+		}
 
-						switch statEnum {
-						case 36:
-							immunities.Physical = statValue
-						case 37:
-							immunities.Magic = statValue
-						case 39:
-							immunities.Fire = statValue
-						case 41:
-							immunities.Light = statValue
-						case 43:
-							immunities.Cold = statValue
-						case 45:
-							immunities.Poison = statValue
-						case 6:
-							hp = statValue >> 8
-						case 7:
-							maxhp = statValue >> 8
-						}
-					}
+		mob := globals.Mob{
+			TxtFileNo:      txtFileNo,
+			Mode:           unit.Mode,
+			Pos:            globals.UnitPosition{X: monxFloat, Y: monyFloat},
+			IsUnique:       monsterData.IsUnique,
+			IsBoss:         isBoss,
+			MonsterFlag:    monsterData.MonsterFlag,
+			IsPlayerMinion: isPlayerMinion,
+			TextTitle:      textTitle,
+			SuperUnique:    superUniqueName,
+			Immunities:     immunities,
+			HP:             hp,
+			MaxHP:          maxhp,
+			IsTownNPC:      isTownNPC,
+			IsHovered:      isHovered,
+			DwOwnerId:      monsterData.OwnerId,
+			MobType:        unit.Type,
+			IsCorpse:       isCorpse,
+		}
 
-					if currentHoveringUnitId != 0 && currentHoveringUnitId == unitId && isTownNPC == "" {
-						isHovered = true
-					}
-				}
+		if isHovered {
+			hoveredMob = mob
+		}
 
-				mob := globals.Mob{
-					TxtFileNo:      txtFileNo,
-					Mode:           mode,
-					Pos:            globals.UnitPosition{X: monxFloat, Y: monyFloat},
-					IsUnique:       isUnique,
-					IsBoss:         isBoss,
-					MonsterFlag:    monsterFlag,
-					IsPlayerMinion: isPlayerMinion,
-					TextTitle:      textTitle,
-					Immunities:     immunities,
-					HP:             hp,
-					MaxHP:          maxhp,
-					IsTownNPC:      isTownNPC,
-					IsHovered:      isHovered,
-					DwOwnerId:      dwOwnerId,
-					MobType:        mobType,
-					IsCorpse:       isCorpse,
-				}
+		mobs = append(mobs, mob)
+	}
+	return mobs, hoveredMob, walkErr
+}
 
-				if isHovered {
-					globals.HoveredMob = mob
-				}
+// planMobReads loads the unit data, path and stat list of every shown mob in
+// two batched phases: the structures the unit points to, then the stat arrays.
+func planMobReads(d2r utils.ProcessReader, mobUnits []UnitAny) {
+	plan := utils.NewReadPlan(d2r)
+	if !plan.Enabled() {
+		return
+	}
 
-				globals.Mobs = append(globals.Mobs, mob)
-			}
+	for _, unit := range mobUnits {
+		if HideNPC(unit.TxtFileNo) {
+			continue
+		}
+		plan.Add(uintptr(unit.UnitData), uint32(utils.SizeOf(MonsterData{})))
+		plan.Add(uintptr(unit.Path), uint32(utils.SizeOf(Path{})))
+		plan.Add(uintptr(unit.StatListEx), uint32(utils.SizeOf(StatListEx{})))
+		plan.Add(uintptr(unit.StatListEx+statListExOwnerFlags), 4)
+	}
+	plan.Execute()
 
-			// Get next mobUnit
-			nextMobUnit, err := utils.ReadAndAssert[int64](d2r, uintptr(mobUnit+0x150), "Int64")
-			utils.IfError(err, "Failed to read next mobUnit")
-			if nextMobUnit == int64(mobUnit) || nextMobUnit == 0 {
-				break
-			}
-			mobUnit = uint64(nextMobUnit)
+	for _, unit := range mobUnits {
+		if HideNPC(unit.TxtFileNo) {
+			continue
+		}
+		statList, err := utils.ReadStruct[StatListEx](d2r, uintptr(unit.StatListEx))
+		if err == nil && statList.StatCount > 0 && statList.StatCount <= maxStatCount {
+			plan.Add(uintptr(statList.Stats), statList.StatCount*statSize)
 		}
 	}
+	plan.Execute()
 }
 
 func getBossName(txtFileNo uint32) string {
@@ -0,0 +1,49 @@
+// memory/readmobs_test.go
+
+package memory
+
+import (
+	"testing"
+
+	"GalyMap/globals"
+)
+
+// TestReadMobsCorpse checks that the corpse flag is read from the unit, at
+// the same offset as for players, and not from the monster data.
+func TestReadMobsCorpse(t *testing.T) {
+	fp := newUnitProcess(0x8000)
+	block := func(i int) uintptr { return heapAddress(t, fp, i) }
+
+	// Each mob has its unit, monster data, path, stat list and a stat array
+	putMob := func(first int, unitID uint32, next uintptr, unitCorpseFlag, dataCorpseFlag uint8) uintptr {
+		unit, data, path, statList, stats := block(first), block(first+1), block(first+2), block(first+3), block(first+4)
+		putUnit(fp, testUnit{address: unit, unitType: unitMonster, txtFileNo: 5, unitID: unitID, unitData: data, path: path, statList: statList, next: next, corpseFlag: unitCorpseFlag})
+		fp.Put(data+0x1A6, []byte{dataCorpseFlag})
+		fp.PutUint16(path+0x02, 5100)
+		fp.PutUint16(path+0x06, 5200)
+		fp.PutUint64(statList+0x30, uint64(stats))
+		fp.PutUint32(statList+0x38, 2)
+		fp.Put(stats, []byte{0, 0, 6, 0, 0, 0x0A, 0, 0, 0, 0, 7, 0, 0, 0x14, 0, 0})
+		return unit
+	}
+	alive := putMob(20, 2, 0, 0, 1)
+	corpse := putMob(0, 1, alive, 1, 0)
+	putBucket(fp, unitMonster, 0, corpse)
+
+	mobs, _, err := ReadMobs(fp, testUnitTable, 0)
+	if err != nil {
+		t.Fatalf("ReadMobs: %v", err)
+	}
+	if len(mobs) != 2 {
+		t.Fatalf("ReadMobs returned %d mobs, want 2", len(mobs))
+	}
+	for i, want := range []struct {
+		isCorpse bool
+		hp       uint32
+	}{{true, 10}, {false, 10}} {
+		mob := mobs[i]
+		if mob.IsCorpse != want.isCorpse || mob.HP != want.hp || mob.MaxHP != 20 || mob.Pos != (globals.UnitPosition{X: 5100, Y: 5200}) {
+			t.Errorf("mob %d = %+v, want IsCorpse %v with 10/20 HP at 5100, 5200", i, mob, want.isCorpse)
+		}
+	}
+}
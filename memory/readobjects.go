@@ -2,97 +2,76 @@ package memory
 
 import (
 	"GalyMap/globals"
+	"GalyMap/mapdata"
 	"GalyMap/utils"
-	"encoding/binary"
 )
 
-// ReadObjects reads objects from the game process and updates the global gameObjects list
-func ReadObjects(d2r *utils.ClassMemory, startingOffset int, currentHoveringUnitId uint32, levelNo int) {
-	globals.GameObjects = []globals.Object{} // Initialize the global gameObjects list
+// ReadObjects reads the shrines, portals, chests and marker objects from the game process
+func ReadObjects(d2r utils.ProcessReader, startingOffset int, currentHoveringUnitId uint32, levelNo int) ([]globals.Object, error) {
+	gameObjects := []globals.Object{}
 
-	baseAddress := d2r.BaseAddress + uintptr(startingOffset) + (2 * 1024)
-	unitTableBuffer, err := d2r.ReadRaw(baseAddress, 128*8)
-	utils.IfError(err, "Failed to read unit table buffer")
-
-	for i := 0; i < 128; i++ {
-		offset := 8 * i
-		objectUnit := int64(binary.LittleEndian.Uint64(unitTableBuffer[offset : offset+8]))
-
-		for objectUnit > 0 {
-			objectBuffer, err := d2r.ReadRaw(uintptr(objectUnit), 0x150+8) // Read a larger chunk of memory
-			utils.IfError(err, "Failed to read object buffer")
-
-			itemType, err := utils.ReadBufferAndAssert[uint32](objectBuffer, 0x00, "UInt")
-			utils.IfError(err, "Failed to read item type")
-
-			if itemType == 2 { // 2 == object
-				txtFileNo, err := utils.ReadBufferAndAssert[uint32](objectBuffer, 0x04, "UInt")
-				utils.IfError(err, "Failed to read txtFileNo")
-
-				mode, err := utils.ReadBufferAndAssert[uint32](objectBuffer, 0x0c, "UInt")
-				utils.IfError(err, "Failed to read mode")
-
-				isPortal, _ := IsPortal(int(txtFileNo))
-				isShrine, _ := IsShrine(int(txtFileNo))
-				isRedPortal, _ := IsRedPortal(int(txtFileNo))
-				isChest, _ := IsChest(int(txtFileNo))
-
-				if isPortal || isShrine || isRedPortal || isChest {
-					unitId, err := utils.ReadBufferAndAssert[uint32](objectBuffer, 0x08, "UInt")
-					utils.IfError(err, "Failed to read unitId")
-
-					interactType, err := utils.ReadBufferAndAssert[uint8](objectBuffer, 0x18+0x08, "UChar")
-					utils.IfError(err, "Failed to read interactType")
+	err := WalkUnits(d2r, uintptr(startingOffset), unitObject, func(_ uintptr, unit UnitAny) bool {
+		if unit.Type != unitObject { // 2 == object
+			return true
+		}
+		txtFileNo := unit.TxtFileNo
 
-					shrineFlag, err := utils.ReadBufferAndAssert[uint16](objectBuffer, 0x18+0x09, "UShort")
-					utils.IfError(err, "Failed to read shrineFlag")
+		isPortal, _ := IsPortal(int(txtFileNo))
+		isShrine, _ := IsShrine(int(txtFileNo))
+		isRedPortal, _ := IsRedPortal(int(txtFileNo))
+		isChest, _ := IsChest(int(txtFileNo))
+		isMarker := mapdata.IsMarkerObject(int(txtFileNo))
 
-					name := GetObjectName(int(txtFileNo))
+		if !isPortal && !isShrine && !isRedPortal && !isChest && !isMarker {
+			return true
+		}
 
-					x, err := utils.ReadBufferAndAssert[uint16](objectBuffer, 0x38+0x10, "UShort")
-					utils.IfError(err, "Failed to read x")
+		// Units that are freed while being read are skipped
+		objectData, err := utils.ReadStruct[ObjectData](d2r, uintptr(unit.UnitData))
+		if err != nil {
+			return true
+		}
 
-					y, err := utils.ReadBufferAndAssert[uint16](objectBuffer, 0x38+0x14, "UShort")
-					utils.IfError(err, "Failed to read y")
+		name := GetObjectName(int(txtFileNo))
 
-					var shrineType, chestState, ownerName string
-					if isShrine {
-						shrineType = GetShrineType(int(interactType))
-					}
-					if isChest {
-						chestState = GetChestState(int(interactType))
-					}
-					if isPortal {
-						// Attempt to read a null-terminated string of up to 32 bytes
-						ownerNameBuffer := objectBuffer[0x18+0x34 : 0x18+0x34+32]
-						ownerName = utils.ReadNullTerminatedString(ownerNameBuffer)
-					}
+		path, err := utils.ReadStruct[StaticPath](d2r, uintptr(unit.Path))
+		if err != nil {
+			return true
+		}
 
-					gameObject := globals.Object{
-						TxtFileNo:    txtFileNo,
-						Name:         name,
-						Mode:         mode,
-						IsChest:      isChest,
-						ChestState:   chestState,
-						IsPortal:     isPortal,
-						IsRedPortal:  isRedPortal,
-						OwnerName:    ownerName,
-						InteractType: interactType,
-						IsShrine:     isShrine,
-						ShrineType:   shrineType,
-						Pos:          globals.ObjectPosition{X: x, Y: y},
-						LevelNo:      levelNo,
-						UnitID:       unitId,
-						ShrineFlag:   shrineFlag,
-					}
-					globals.GameObjects = append(globals.GameObjects, gameObject)
-				}
+		var shrineType, chestState, ownerName string
+		if isShrine {
+			shrineType = GetShrineType(int(objectData.InteractType))
+		}
+		if isChest {
+			chestState = GetChestState(int(objectData.InteractType))
+		}
+		if isPortal {
+			// The owner name is a null-terminated string of up to 32 bytes
+			ownerName = utils.ReadNullTerminatedString(objectData.Owner[:])
+		}
 
-				objectUnit, err = utils.ReadBufferAndAssert[int64](objectBuffer, 0x150, "Int64")
-				utils.IfError(err, "Failed to read next objectUnit")
-			}
+		gameObject := globals.Object{
+			TxtFileNo:    txtFileNo,
+			Name:         name,
+			Mode:         unit.Mode,
+			IsChest:      isChest,
+			ChestState:   chestState,
+			IsPortal:     isPortal,
+			IsRedPortal:  isRedPortal,
+			OwnerName:    ownerName,
+			InteractType: objectData.InteractType,
+			IsShrine:     isShrine,
+			ShrineType:   shrineType,
+			Pos:          globals.ObjectPosition{X: path.X, Y: path.Y},
+			LevelNo:      levelNo,
+			UnitID:       unit.UnitId,
+			ShrineFlag:   objectData.ShrineFlag,
 		}
-	}
+		gameObjects = append(gameObjects, gameObject)
+		return true
+	})
+	return gameObjects, err
 }
 
 // Getters
@@ -0,0 +1,47 @@
+// memory/readobjects_test.go
+
+package memory
+
+import (
+	"reflect"
+	"testing"
+
+	"GalyMap/globals"
+)
+
+func TestReadObjects(t *testing.T) {
+	fp := newUnitProcess(0x2000)
+	block := func(i int) uintptr { return heapAddress(t, fp, i) }
+
+	// A shrine, then a town portal and a barrel in the same bucket, and a chest in another one
+	shrine, portal, barrel, chest := block(0), block(3), block(6), block(8)
+	putUnit(fp, testUnit{address: shrine, unitType: unitObject, txtFileNo: 2, unitID: 10, unitData: block(1), path: block(2)})
+	fp.Put(block(1)+0x08, []byte{5})
+	putStaticPath(fp, block(2), 5100, 5200)
+
+	putUnit(fp, testUnit{address: portal, unitType: unitObject, txtFileNo: 59, unitID: 11, mode: 1, unitData: block(4), path: block(5), next: barrel})
+	fp.Put(block(4)+0x34, []byte("Gälyna\x00"))
+	putStaticPath(fp, block(5), 5110, 5210)
+
+	putUnit(fp, testUnit{address: barrel, unitType: unitObject, txtFileNo: 7, unitID: 12, next: chest})
+
+	putUnit(fp, testUnit{address: chest, unitType: unitObject, txtFileNo: 5, unitID: 13, unitData: block(9), path: block(10)})
+	fp.Put(block(9)+0x08, []byte{128})
+	putStaticPath(fp, block(10), 5120, 5220)
+
+	putBucket(fp, unitObject, 3, shrine)
+	putBucket(fp, unitObject, 9, portal)
+
+	objects, err := ReadObjects(fp, testUnitTable, 0, 2)
+	if err != nil {
+		t.Fatalf("ReadObjects: %v", err)
+	}
+	want := []globals.Object{
+		{TxtFileNo: 2, Name: "Shrine", IsShrine: true, ShrineType: "ShrId5", InteractType: 5, Pos: globals.ObjectPosition{X: 5100, Y: 5200}, LevelNo: 2, UnitID: 10},
+		{TxtFileNo: 59, Name: "TownPortal", Mode: 1, IsPortal: true, OwnerName: "Gälyna", Pos: globals.ObjectPosition{X: 5110, Y: 5210}, LevelNo: 2, UnitID: 11},
+		{TxtFileNo: 5, Name: "LargeChestRight", IsChest: true, ChestState: "locked", InteractType: 128, Pos: globals.ObjectPosition{X: 5120, Y: 5220}, LevelNo: 2, UnitID: 13},
+	}
+	if !reflect.DeepEqual(objects, want) {
+		t.Errorf("ReadObjects =\n%+v\nwant\n%+v", objects, want)
+	}
+}
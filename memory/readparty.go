@@ -3,31 +3,57 @@ package memory
 import (
 	"GalyMap/globals"
 	"GalyMap/utils"
+	"fmt"
 	// "log"
 )
 
-func ReadParty(d2r *utils.ClassMemory, playerUnitId uint32) {
+// maxPartySize bounds the roster walk, D2R games hold at most 8 players
+const maxPartySize = 8
+
+// ReadParty reads the party roster, which lists every player in the game with their area and position.
+func ReadParty(d2r utils.ProcessReader, playerUnitId uint32) ([]globals.Player, error) {
 	rosterOffset := globals.Offsets.M["rosterOffset"]
-	baseAddress := d2r.BaseAddress + rosterOffset
+	baseAddress := d2r.BaseAddress() + rosterOffset
 	partyStruct, err := utils.ReadAndAssert[int64](d2r, uintptr(baseAddress), "Int64")
-	utils.IfError(err, "Error reading partyStruct")
+	if err != nil {
+		return nil, err
+	}
+
+	partyList := []globals.Player{}
 
 	for partyStruct > 0 {
+		if len(partyList) == maxPartySize {
+			return partyList, fmt.Errorf("party roster at 0x%X has more than %d entries", baseAddress, maxPartySize)
+		}
 		// log.Printf("in party loop")
 		name, err := utils.ReadAndAssert[string](d2r, uintptr(partyStruct), "String", 16)
-		utils.IfError(err, "Error reading name")
+		if err != nil {
+			return partyList, fmt.Errorf("party name: %w", err)
+		}
 		unitId, err := utils.ReadAndAssert[uint32](d2r, uintptr(partyStruct+0x48), "UInt")
-		utils.IfError(err, "Error reading unitId")
+		if err != nil {
+			return partyList, fmt.Errorf("party unitId: %w", err)
+		}
 		area, err := utils.ReadAndAssert[uint32](d2r, uintptr(partyStruct+0x5C), "UInt")
-		utils.IfError(err, "Error reading area")
+		if err != nil {
+			return partyList, fmt.Errorf("party area: %w", err)
+		}
 		plevel, err := utils.ReadAndAssert[uint16](d2r, uintptr(partyStruct+0x58), "UShort")
-		utils.IfError(err, "Error reading plevel")
+		if err != nil {
+			return partyList, fmt.Errorf("party plevel: %w", err)
+		}
 		partyId, err := utils.ReadAndAssert[uint16](d2r, uintptr(partyStruct+0x5A), "UShort")
-		utils.IfError(err, "Error reading partyId")
+		if err != nil {
+			return partyList, fmt.Errorf("party partyId: %w", err)
+		}
 		xPos, err := utils.ReadAndAssert[uint32](d2r, uintptr(partyStruct+0x60), "UInt")
-		utils.IfError(err, "Error reading xPos")
+		if err != nil {
+			return partyList, fmt.Errorf("party xPos: %w", err)
+		}
 		yPos, err := utils.ReadAndAssert[uint32](d2r, uintptr(partyStruct+0x64), "UInt")
-		utils.IfError(err, "Error reading yPos")
+		if err != nil {
+			return partyList, fmt.Errorf("party yPos: %w", err)
+		}
 		// hostilePtr, err := utils.ReadAndAssert[int64](d2r, uintptr(partyStruct+0x70), "Int64")
 		// utils.IfError(err, "Error reading hostilePtr")
 
@@ -58,9 +84,12 @@ func ReadParty(d2r *utils.ClassMemory, playerUnitId uint32) {
 			IsHostileToPlayer: isHostileToPlayer,
 		}
 
-		globals.PartyList = append(globals.PartyList, player)
+		partyList = append(partyList, player)
 		partyStruct, err = utils.ReadAndAssert[int64](d2r, uintptr(partyStruct+0x148), "Int64")
-		utils.IfError(err, "Error reading partyStruct")
+		if err != nil {
+			return partyList, fmt.Errorf("party partyStruct: %w", err)
+		}
 	}
-	// log.Printf("Party list: %v", globals.PartyList)
+	// log.Printf("Party list: %v", partyList)
+	return partyList, nil
 }
@@ -5,8 +5,8 @@ import (
 	"GalyMap/utils"
 )
 
-func ReadUI(d2r *utils.ClassMemory) (bool, error) {
-	base := d2r.BaseAddress + globals.Offsets.M["uiOffset"] - 0xa
+func ReadUI(d2r utils.ProcessReader) (bool, error) {
+	base := d2r.BaseAddress() + globals.Offsets.M["uiOffset"] - 0xa
 	buffer, err := d2r.ReadRaw(base, 32)
 	if err != nil {
 		return false, err
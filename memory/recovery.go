@@ -0,0 +1,77 @@
+// memory/recovery.go
+package memory
+
+import (
+	"GalyMap/utils"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// notInGameBackoffTicks polls for a game about once a second while in the menus
+	notInGameBackoffTicks = 20
+	// maxBackoffTicks caps the exponential backoff after read failures
+	maxBackoffTicks = 40
+)
+
+var recoveryLog = utils.NewRateLimitedLog(10 * time.Second)
+
+// Recovery decides how the tick loop reacts to errors from ReadGameMemory:
+// it backs off while out of game or while reads keep failing, forces a new
+// player scan after stale pointers and resolves the signatures again when one
+// of them is missing.
+type Recovery struct {
+	failures  int // consecutive failed ticks
+	skipTicks int // ticks to wait before the next attempt
+}
+
+// Wait reports whether the tick loop should skip the current tick.
+func (r *Recovery) Wait() bool {
+	if r.skipTicks > 0 {
+		r.skipTicks--
+		return true
+	}
+	return false
+}
+
+// Handle records the result of a tick and prepares the next attempt. d2r is
+// the process itself rather than the tick's CachedReader, since a signature
+// rescan reads the whole module and would only fill the cache.
+func (r *Recovery) Handle(d2r utils.ProcessReader, err error) {
+	if err == nil {
+		if r.failures > 0 {
+			log.Printf("Game memory readable again after %d failed ticks", r.failures)
+		}
+		r.failures = 0
+		return
+	}
+	r.failures++
+
+	switch {
+	case errors.Is(err, ErrNotInGame):
+		r.skipTicks = notInGameBackoffTicks
+		recoveryLog.Printf("notingame", "Not in game, polling every %d ticks", r.skipTicks)
+		return
+
+	case errors.Is(err, ErrSignatureMissing):
+		r.skipTicks = maxBackoffTicks
+		recoveryLog.Printf("signature", "Tick failed: %v; scanning signatures again", err)
+		if err := PatternScan(d2r, true); err != nil {
+			recoveryLog.Printf("rescan", "Signature scan failed: %v", err)
+		}
+		return
+
+	case errors.Is(err, ErrStalePointer):
+		// The player unit moved, e.g. after a level change
+		ResetPlayerPointer()
+	}
+
+	// Back off exponentially after the first retry, rescanning for the player
+	// in case a read fault came from a pointer that went away
+	if r.failures > 1 {
+		r.skipTicks = min(1<<min(r.failures-2, 6), maxBackoffTicks)
+		ResetPlayerPointer()
+	}
+	recoveryLog.Printf("failed", "Tick failed (%d in a row): %v; retrying in %d ticks", r.failures, err, r.skipTicks)
+}
@@ -0,0 +1,104 @@
+// memory/recovery_test.go
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"GalyMap/utils"
+)
+
+// countingReader counts the raw reads made through it
+type countingReader struct {
+	utils.ProcessReader
+	reads int
+}
+
+func (cr *countingReader) ReadRaw(address uintptr, size uint32, offsets ...uintptr) ([]byte, error) {
+	cr.reads++
+	return cr.ProcessReader.ReadRaw(address, size, offsets...)
+}
+
+// waits counts the ticks Recovery skips before the next attempt
+func waits(r *Recovery) int {
+	n := 0
+	for r.Wait() {
+		n++
+	}
+	return n
+}
+
+func TestRecoveryHandle(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantWaits   int
+		wantRescan  bool
+		wantNewScan bool // the player pointer is forgotten
+	}{
+		{"success", nil, 0, false, false},
+		{"not in game", ErrNotInGame, notInGameBackoffTicks, false, false},
+		{"signature missing", fmt.Errorf("player unit: %w", ErrSignatureMissing), maxBackoffTicks, true, false},
+		{"stale pointer", fmt.Errorf("player: %w", ErrStalePointer), 0, false, true},
+		{"read fault", fmt.Errorf("unit table: %w", ErrReadFault), 0, false, false},
+		{"other error", errors.New("decode failed"), 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			process := &countingReader{ProcessReader: scannableProcess(t)}
+			lastPlayerPointer = 0x1234
+			defer ResetPlayerPointer()
+
+			r := &Recovery{}
+			r.Handle(process, tt.err)
+			if got := waits(r); got != tt.wantWaits {
+				t.Errorf("skipped %d ticks, want %d", got, tt.wantWaits)
+			}
+			if rescanned := process.reads > 0; rescanned != tt.wantRescan {
+				t.Errorf("rescanned = %v (%d reads), want %v", rescanned, process.reads, tt.wantRescan)
+			}
+			if newScan := lastPlayerPointer == 0; newScan != tt.wantNewScan {
+				t.Errorf("player pointer reset = %v, want %v", newScan, tt.wantNewScan)
+			}
+		})
+	}
+}
+
+func TestRecoveryBackoff(t *testing.T) {
+	fault := fmt.Errorf("unit table: %w", ErrReadFault)
+	steps := []struct {
+		err       error
+		wantWaits int
+	}{
+		// The first failure is retried on the next tick, then the wait doubles up to the cap
+		{fault, 0},
+		{fault, 1},
+		{fault, 2},
+		{fault, 4},
+		{fault, 8},
+		{fault, 16},
+		{fault, 32},
+		{fault, maxBackoffTicks},
+		{fault, maxBackoffTicks},
+		// A successful tick starts over
+		{nil, 0},
+		{fault, 0},
+		{fault, 1},
+		{fault, 2},
+	}
+
+	process := &countingReader{ProcessReader: scannableProcess(t)}
+	defer ResetPlayerPointer()
+	r := &Recovery{}
+	for i, step := range steps {
+		r.Handle(process, step.err)
+		if got := waits(r); got != step.wantWaits {
+			t.Errorf("step %d (%v): skipped %d ticks, want %d", i, step.err, got, step.wantWaits)
+		}
+	}
+	if process.reads != 0 {
+		t.Errorf("read faults made %d reads, want none", process.reads)
+	}
+}
@@ -4,134 +4,96 @@ import (
 	"GalyMap/globals"
 	"GalyMap/utils"
 	"fmt"
-	"log"
-	"strings"
+	"time"
 )
 
-var lastPlayerPointer uintptr
+var (
+	lastPlayerPointer uintptr
 
-func ScanForPlayer(d2r *utils.ClassMemory, startingOffset uintptr) uintptr {
+	// playerLog keeps the player scan from logging on every tick while out of game
+	playerLog = utils.NewRateLimitedLog(30 * time.Second)
+)
+
+// ResetPlayerPointer forces the next ScanForPlayer to search the unit table again.
+func ResetPlayerPointer() {
+	lastPlayerPointer = 0
+}
+
+func ScanForPlayer(d2r utils.ProcessReader, startingOffset uintptr) uintptr {
 	if CheckPlayerPointer(d2r, lastPlayerPointer) {
 		return lastPlayerPointer
 	} else {
-		log.Printf("Scanning for new player pointer %v, starting default offset %v", lastPlayerPointer, startingOffset)
-		log.Printf("base address: 0x%x", d2r.BaseAddress)
-		log.Printf("unit table offset: 0x%x", startingOffset)
-		log.Printf("unit table address: 0x%x", d2r.BaseAddress+startingOffset)
+		playerLog.Printf("scan", "Scanning for new player pointer %v, base address 0x%x, unit table offset 0x%x", lastPlayerPointer, d2r.BaseAddress(), startingOffset)
 		lastPlayerPointer = GetPlayerOffset(d2r, startingOffset)
 	}
 	return lastPlayerPointer
 }
 
-func CheckPlayerPointer(d2r *utils.ClassMemory, playerUnit uintptr) bool {
+func CheckPlayerPointer(d2r utils.ProcessReader, playerUnit uintptr) bool {
 	if playerUnit == 0 {
-		log.Println("Player unit pointer is null.")
+		playerLog.Printf("null", "Player unit pointer is null.")
 		return false
 	}
 
-	// Read actAddress
-	pAct := playerUnit + 0x20
-	actAddressUInt64, err := utils.ReadAndAssert[uint64](d2r, pAct, "UInt64")
-	if err != nil || actAddressUInt64 == 0 {
-		log.Printf("Error reading act address at 0x%X: %v", pAct, err)
+	unit, err := utils.ReadStruct[UnitAny](d2r, playerUnit)
+	if err != nil {
+		playerLog.Printf("unit", "Error reading player unit at 0x%X: %v", playerUnit, err)
 		return false
 	}
-	actAddress := uintptr(actAddressUInt64)
 
 	// Read mapSeed
-	mapSeedAddress := actAddress + 0x1C
-	mapSeed, err := utils.ReadAndAssert[uint32](d2r, mapSeedAddress, "UInt")
-	if err != nil || mapSeed == 0 {
-		log.Printf("Error reading map seed at 0x%X: %v", mapSeedAddress, err)
+	act, err := utils.ReadStruct[Act](d2r, uintptr(unit.Act))
+	if err != nil || act.MapSeed == 0 {
+		playerLog.Printf("act", "Error reading map seed from act at 0x%X: %v", unit.Act, err)
 		return false
 	}
 
-	// Read pathAddress
-	pPath := playerUnit + 0x38
-	pathAddress, err := utils.ReadAndAssert[uintptr](d2r, pPath, "Int64")
-	if err != nil || pathAddress == 0 {
-		log.Printf("Error reading path address at 0x%X: %v", pathAddress, err)
-		return false
-	}
-
-	// Read xPos
-	xPosAddress := pathAddress + 0x02
-	xPos, err := utils.ReadAndAssert[uint16](d2r, xPosAddress, "UShort")
-	if err != nil || xPos == 0 {
-		log.Printf("Error reading x position at 0x%X: %v", xPosAddress, err)
-		return false
-	}
-
-	// Read yPos
-	yPosAddress := pathAddress + 0x06
-	yPos, err := utils.ReadAndAssert[uint16](d2r, yPosAddress, "UShort")
-	if err != nil || yPos == 0 {
-		log.Printf("Error reading y position at 0x%X: %v", yPosAddress, err)
+	// Read position
+	path, err := utils.ReadStruct[Path](d2r, uintptr(unit.Path))
+	if err != nil || path.X == 0 || path.Y == 0 {
+		playerLog.Printf("path", "Error reading position from path at 0x%X: %v", unit.Path, err)
 		return false
 	}
 
 	// If all values are valid
-	log.Printf("Player pointer valid. xPos: %d, yPos: %d, mapSeed: %d", xPos, yPos, mapSeed)
+	playerLog.Printf("valid", "Player pointer valid. xPos: %d, yPos: %d, mapSeed: %d", path.X, path.Y, act.MapSeed)
 	return true
 }
 
-func GetPlayerOffset(d2r *utils.ClassMemory, startingOffset uintptr) uintptr {
-	for attempts := 1; attempts <= 128; attempts++ {
-		newOffset := startingOffset + uintptr((attempts-1)*8)
-		startingAddress := d2r.BaseAddress + newOffset
-
-		playerUnit, err := utils.ReadAndAssert[int64](d2r, startingAddress, "Int64")
-		utils.IfError(err, "Error reading player unit address")
-		for playerUnit > 0 {
-			pInventory := playerUnit + 0x90
-			inventory, err := utils.ReadAndAssert[int64](d2r, uintptr(pInventory), "Int64")
-			utils.IfError(err, "Error reading inventory address")
-			if inventory != 0 {
-				expCharPtr, err := utils.ReadAndAssert[int64](d2r, d2r.BaseAddress+globals.Offsets.M["expOffset"], "Int64")
-				utils.IfError(err, "Error reading expCharPtr")
-				expChar, err := utils.ReadAndAssert[uint16](d2r, uintptr(expCharPtr+0x5C), "UShort")
-				utils.IfError(err, "Error reading expChar")
-				var basecheck bool
-				val, err := utils.ReadAndAssert[uint16](d2r, uintptr(inventory+0x30), "UShort")
-				utils.IfError(err, "Error reading basecheck 1")
-				basecheck = val != 1
-				if expChar != 0 {
-					val, err := utils.ReadAndAssert[uint16](d2r, uintptr(inventory+0x70), "UShort")
-					utils.IfError(err, "Error reading basecheck 1")
-					basecheck = val != 0
-				}
-
-				if basecheck {
-					pAct := playerUnit + 0x20
-					actAddress, err := utils.ReadAndAssert[int64](d2r, uintptr(pAct), "Int64")
-					utils.IfError(err, "Error reading act address")
-					mapSeedAddress := actAddress + 0x1C
-					mapSeed, err := utils.ReadAndAssert[uint32](d2r, uintptr(mapSeedAddress), "UInt")
-					utils.IfError(err, "Error reading map seed")
-
-					pPath := playerUnit + 0x38
-					pathAddress, _ := utils.ReadAndAssert[int64](d2r, uintptr(pPath), "Int64")
-					xPos, _ := utils.ReadAndAssert[uint16](d2r, uintptr(pathAddress+0x02), "UShort")
-					yPos, _ := utils.ReadAndAssert[uint16](d2r, uintptr(pathAddress+0x06), "UShort")
+func GetPlayerOffset(d2r utils.ProcessReader, startingOffset uintptr) uintptr {
+	var playerUnit uintptr
+	err := WalkUnits(d2r, startingOffset, unitPlayer, func(address uintptr, unit UnitAny) bool {
+		if unit.Inventory == 0 {
+			return true
+		}
+		expCharPtr, err := utils.ReadAndAssert[int64](d2r, d2r.BaseAddress()+globals.Offsets.M["expOffset"], "Int64")
+		utils.IfError(err, "Error reading expCharPtr")
+		expChar, err := utils.ReadAndAssert[uint16](d2r, uintptr(expCharPtr+0x5C), "UShort")
+		utils.IfError(err, "Error reading expChar")
+		inventory, err := utils.ReadStruct[Inventory](d2r, uintptr(unit.Inventory))
+		utils.IfError(err, "Error reading inventory")
+		basecheck := inventory.BaseCheck != 1
+		if expChar != 0 {
+			basecheck = inventory.BaseCheckExp != 0
+		}
+		if !basecheck {
+			return true
+		}
 
-					pUnitData := playerUnit + 0x10
-					playerNameAddress, _ := utils.ReadAndAssert[int64](d2r, uintptr(pUnitData), "Int64")
-					var name strings.Builder
-					for i := 0; i < 16; i++ {
-						char, _ := utils.ReadAndAssert[byte](d2r, uintptr(playerNameAddress+int64(i)), "UChar")
-						name.WriteByte(char)
-					}
+		act, err := utils.ReadStruct[Act](d2r, uintptr(unit.Act))
+		utils.IfError(err, "Error reading map seed")
+		path, _ := utils.ReadStruct[Path](d2r, uintptr(unit.Path))
 
-					if xPos > 0 && yPos > 0 && len(fmt.Sprintf("%v", mapSeed)) > 6 {
-						// log.Printf("SUCCESS: Found current player offset: %v, %v %v at entry %v, which gives obfuscated map seed: %v", newOffset, xPos, yPos, attempts, mapSeed)
-						return uintptr(playerUnit)
-					}
-				}
-			}
-			playerUnit, err = utils.ReadAndAssert[int64](d2r, uintptr(playerUnit+0x150), "Int64")
-			utils.IfError(err, "Error reading next player unit")
+		if path.X > 0 && path.Y > 0 && len(fmt.Sprintf("%v", act.MapSeed)) > 6 {
+			// log.Printf("SUCCESS: Found current player offset: %v, %v %v, which gives obfuscated map seed: %v", address, path.X, path.Y, act.MapSeed)
+			playerUnit = address
+			return false
 		}
+		return true
+	})
+	utils.IfError(err, "Error walking player units")
+	if playerUnit == 0 {
+		playerLog.Printf("notfound", "Did not find a player offset in unit hashtable, likely in game menu.")
 	}
-	log.Println("Did not find a player offset in unit hashtable, likely in game menu.")
-	return 0
+	return playerUnit
 }
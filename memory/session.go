@@ -0,0 +1,229 @@
+// memory/session.go
+package memory
+
+import (
+	"GalyMap/globals"
+	"GalyMap/utils"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// SessionState is where D2R is between launching and exiting
+type SessionState int32
+
+const (
+	Detached SessionState = iota // no D2R process attached
+	Menu                         // attached, no game joined
+	Loading                      // player unit present but not readable yet, or between areas
+	InGame                       // player state is being read every tick
+	Exiting                      // shutting down, no more reads
+)
+
+func (s SessionState) String() string {
+	switch s {
+	case Detached:
+		return "Detached"
+	case Menu:
+		return "Menu"
+	case Loading:
+		return "Loading"
+	case InGame:
+		return "InGame"
+	case Exiting:
+		return "Exiting"
+	default:
+		return "Unknown"
+	}
+}
+
+// loadingTimeout is how long a game may stay in Loading before it counts as left
+const loadingTimeout = 10 * time.Second
+
+// GameInfo describes one joined game
+type GameInfo struct {
+	PlayerName string
+	UnitId     uint32
+	MapSeed    uint32
+	Difficulty uint16
+	LevelNo    uint32
+	JoinedAt   time.Time
+}
+
+type SessionEventType int
+
+const (
+	GameJoined SessionEventType = iota
+	GameLeft
+)
+
+func (t SessionEventType) String() string {
+	if t == GameJoined {
+		return "GameJoined"
+	}
+	return "GameLeft"
+}
+
+// SessionEvent is sent to session listeners when a game is joined or left
+type SessionEvent struct {
+	Type     SessionEventType
+	Game     GameInfo
+	Duration time.Duration // time spent in the game, for GameLeft
+}
+
+// Session tracks the game session from the result of each tick. It is driven
+// from the tick goroutine; State may be called from any goroutine.
+type Session struct {
+	state        atomic.Int32
+	game         GameInfo
+	loadingSince time.Time
+	listeners    []func(SessionEvent)
+}
+
+// NewSession returns a Detached session. Memory trackers are reset whenever a game is left.
+func NewSession() *Session {
+	s := &Session{}
+	s.Subscribe(func(event SessionEvent) {
+		if event.Type == GameLeft {
+			ResetTrackers()
+		}
+	})
+	return s
+}
+
+// State returns the current session state.
+func (s *Session) State() SessionState {
+	return SessionState(s.state.Load())
+}
+
+// Game returns the game being played, valid while InGame or Loading.
+func (s *Session) Game() GameInfo {
+	return s.game
+}
+
+// Subscribe registers fn to be called on the tick goroutine for every session event.
+func (s *Session) Subscribe(fn func(SessionEvent)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// Update advances the session with the outcome of a ReadGameMemory tick.
+// Ticks that succeed put the session in game, ErrNotInGame and ErrStalePointer
+// move it to Loading or Menu depending on whether the unit table still holds a
+// player, and other errors leave it where it is.
+func (s *Session) Update(d2r utils.ProcessReader, err error) {
+	state := s.State()
+	if state == Exiting {
+		return
+	}
+
+	if err == nil {
+		current := globals.CurrentGameState()
+		info := GameInfo{
+			PlayerName: current.PlayerName,
+			UnitId:     current.UnitId,
+			MapSeed:    current.MapSeed,
+			Difficulty: current.Difficulty,
+			LevelNo:    current.LevelNo,
+		}
+		switch {
+		case state != InGame && state != Loading:
+			s.join(info)
+		case info.UnitId != s.game.UnitId || info.PlayerName != s.game.PlayerName ||
+			(s.game.MapSeed != 0 && info.MapSeed != 0 && info.MapSeed != s.game.MapSeed):
+			// A different game was joined, or with another character, without
+			// the menu being seen in between
+			s.leave(Menu)
+			s.join(info)
+		default:
+			if state == Loading {
+				s.setState(InGame)
+			}
+			if s.game.MapSeed == 0 {
+				s.game.MapSeed = info.MapSeed
+			}
+			s.game.LevelNo = info.LevelNo
+		}
+		return
+	}
+
+	if !errors.Is(err, ErrNotInGame) && !errors.Is(err, ErrStalePointer) {
+		return
+	}
+
+	playerPresent := false
+	if unitTable, ok := globals.GetOffset("unitTable"); ok {
+		playerPresent, _ = IsInGame(d2r, unitTable)
+	}
+
+	switch state {
+	case Detached:
+		s.setState(Menu)
+	case InGame:
+		if playerPresent {
+			s.loadingSince = time.Now()
+			s.setState(Loading)
+		} else {
+			s.leave(Menu)
+		}
+	case Loading:
+		if !playerPresent || time.Since(s.loadingSince) > loadingTimeout {
+			s.leave(Menu)
+		}
+	}
+}
+
+// Detach marks the D2R process as gone, leaving the current game if any.
+func (s *Session) Detach() {
+	s.leave(Detached)
+}
+
+// Exit leaves the current game if any and stops the session.
+func (s *Session) Exit() {
+	s.leave(Exiting)
+}
+
+func (s *Session) join(info GameInfo) {
+	info.JoinedAt = time.Now()
+	s.game = info
+	s.setState(InGame)
+	s.emit(SessionEvent{Type: GameJoined, Game: info})
+}
+
+// leave moves to next, sending GameLeft if a game was in progress.
+func (s *Session) leave(next SessionState) {
+	state := s.State()
+	s.setState(next)
+	if state == InGame || state == Loading {
+		s.emit(SessionEvent{Type: GameLeft, Game: s.game, Duration: time.Since(s.game.JoinedAt)})
+		s.game = GameInfo{}
+	}
+}
+
+func (s *Session) setState(next SessionState) {
+	if previous := SessionState(s.state.Swap(int32(next))); previous != next {
+		log.Printf("Session state %v -> %v", previous, next)
+	}
+}
+
+func (s *Session) emit(event SessionEvent) {
+	for _, fn := range s.listeners {
+		fn(event)
+	}
+}
+
+// ResetTrackers clears everything carried over between ticks so that the next
+// game starts from scratch: the player pointer, the seed and stat caches, the
+// published GameState, which the readers that don't run every tick start from.
+func ResetTrackers() {
+	ResetPlayerPointer()
+	lastdwInitSeedHash1 = 0
+	lastdwInitSeedHash2 = 0
+	playerLevel = 0
+	experience = 0
+	lastHoveredType = 0
+	lastHoveredUnitId = 0
+
+	globals.MapSeed = 0
+	globals.ResetGameState()
+}
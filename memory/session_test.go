@@ -0,0 +1,96 @@
+// memory/session_test.go
+
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"GalyMap/globals"
+)
+
+func TestSessionUpdate(t *testing.T) {
+	globals.InitSettings()
+
+	// The unit table holds a player unit while present is set
+	fp := newUnitProcess(0x400)
+	player, path := heapAddress(t, fp, 0), heapAddress(t, fp, 1)
+	putUnit(fp, testUnit{address: player, unitType: unitPlayer, unitID: 1, path: path})
+	fp.PutUint16(path+0x02, 5012)
+	fp.PutUint16(path+0x06, 5008)
+	setPresent := func(present bool) {
+		if present {
+			putBucket(fp, unitPlayer, 0, player)
+		} else {
+			putBucket(fp, unitPlayer, 0, 0)
+		}
+	}
+	saved, hadOffset := globals.GetOffset("unitTable")
+	globals.SetOffset("unitTable", testUnitTable)
+	t.Cleanup(func() {
+		if hadOffset {
+			globals.SetOffset("unitTable", saved)
+		}
+		globals.ResetGameState()
+	})
+
+	alice := &globals.GameState{PlayerName: "Alice", UnitId: 1, MapSeed: 100, Difficulty: 2, LevelNo: 1}
+	moved := &globals.GameState{PlayerName: "Alice", UnitId: 1, MapSeed: 100, Difficulty: 2, LevelNo: 2}
+	bob := &globals.GameState{PlayerName: "Bob", UnitId: 1, MapSeed: 100, Difficulty: 2, LevelNo: 2}
+	nextGame := &globals.GameState{PlayerName: "Bob", UnitId: 1, MapSeed: 200, Difficulty: 2, LevelNo: 1}
+	readFault := fmt.Errorf("unit table: %w", ErrReadFault)
+
+	steps := []struct {
+		name       string
+		state      *globals.GameState // published before a successful tick
+		err        error
+		present    bool
+		wantState  SessionState
+		wantEvents []string
+	}{
+		{"menu", nil, ErrNotInGame, false, Menu, nil},
+		{"join", alice, nil, true, InGame, []string{"GameJoined Alice"}},
+		{"in game", alice, nil, true, InGame, nil},
+		{"read error mid-game", nil, readFault, true, InGame, nil},
+		{"read error with the player gone", nil, readFault, false, InGame, nil},
+		{"area change", nil, ErrStalePointer, true, Loading, nil},
+		{"loaded", moved, nil, true, InGame, nil},
+		{"player name change", bob, nil, true, InGame, []string{"GameLeft Alice", "GameJoined Bob"}},
+		{"game change", nextGame, nil, true, InGame, []string{"GameLeft Bob", "GameJoined Bob"}},
+		{"leave", nil, ErrNotInGame, false, Menu, []string{"GameLeft Bob"}},
+		{"still in the menu", nil, ErrNotInGame, false, Menu, nil},
+		{"rejoin", alice, nil, true, InGame, []string{"GameJoined Alice"}},
+	}
+
+	session := NewSession()
+	var events []string
+	session.Subscribe(func(event SessionEvent) {
+		events = append(events, fmt.Sprintf("%v %s", event.Type, event.Game.PlayerName))
+	})
+	for _, step := range steps {
+		setPresent(step.present)
+		if step.state != nil {
+			globals.PublishGameState(step.state)
+		}
+		events = nil
+		session.Update(fp, step.err)
+		if got := session.State(); got != step.wantState {
+			t.Errorf("%s: state %v, want %v", step.name, got, step.wantState)
+		}
+		if !slices.Equal(events, step.wantEvents) {
+			t.Errorf("%s: events %q, want %q", step.name, events, step.wantEvents)
+		}
+	}
+
+	// Detaching leaves the game once
+	events = nil
+	session.Detach()
+	session.Detach()
+	if want := []string{"GameLeft Alice"}; !slices.Equal(events, want) {
+		t.Errorf("detach: events %q, want %q", events, want)
+	}
+	if got := session.State(); got != Detached {
+		t.Errorf("detach: state %v, want %v", got, Detached)
+	}
+}
@@ -0,0 +1,58 @@
+// memory/snapshot.go
+
+package memory
+
+import (
+	"GalyMap/globals"
+	"GalyMap/utils"
+	"log"
+	"sync/atomic"
+)
+
+// CaptureSnapshot records one full ReadGameMemory tick against d2r and saves
+// every page it touched, along with the module image, to filePath.
+func CaptureSnapshot(d2r utils.ProcessReader, settings map[string]bool, filePath string) error {
+	recorder, err := utils.NewRecordingReader(d2r)
+	if err != nil {
+		return err
+	}
+
+	// Force a fresh player scan and a tick where every periodic read runs,
+	// so that replaying the snapshot walks exactly the same pages.
+	ResetPlayerPointer()
+	snap := recorder.Snapshot()
+	snap.Tick = 0
+	if err := runTick(recorder, settings, snap.Tick); err != nil {
+		return err
+	}
+
+	if err := utils.SaveSnapshot(filePath, snap); err != nil {
+		return err
+	}
+	log.Printf("Saved memory snapshot with %d pages to %s", len(snap.Pages), filePath)
+	return nil
+}
+
+// ReplaySnapshot loads a snapshot, resolves the offsets from its module image
+// and runs ReadGameMemory against it, leaving the results in globals. Nothing
+// is written to config/.
+func ReplaySnapshot(filePath string, settings map[string]bool) error {
+	reader, err := utils.OpenSnapshot(filePath)
+	if err != nil {
+		return err
+	}
+	if err := PatternScan(reader, false); err != nil {
+		return err
+	}
+
+	ResetPlayerPointer()
+	return runTick(reader, settings, reader.Snapshot().Tick)
+}
+
+// runTick runs ReadGameMemory with the tick counter pinned to tick.
+func runTick(d2r utils.ProcessReader, settings map[string]bool, tick int64) error {
+	savedTick := atomic.SwapInt64(&globals.Ticktock, tick)
+	defer atomic.StoreInt64(&globals.Ticktock, savedTick)
+
+	return ReadGameMemory(d2r, settings)
+}
@@ -0,0 +1,126 @@
+// memory/supervisor.go
+package memory
+
+import (
+	"GalyMap/utils"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// handleCheckInterval is how often the process handle is checked for exit
+	handleCheckInterval = time.Second
+	// reattachInterval is how often a new D2R process is looked for while detached
+	reattachInterval = 2 * time.Second
+)
+
+var attachLog = utils.NewRateLimitedLog(time.Minute)
+
+// Supervisor keeps the tick loop attached to D2R. It notices when the process
+// exits, detaches the session and attaches again to the next D2R process that
+// is started, resolving its signatures before any reads are made.
+//
+// Poll is called from the tick goroutine; Close may be called from any goroutine.
+type Supervisor struct {
+	mu          sync.Mutex
+	closed      bool
+	program     string
+	process     utils.Process
+	session     *Session
+	attach      func(program string) (utils.Process, error)
+	lastCheck   time.Time
+	lastAttempt time.Time
+	changed     bool
+}
+
+// NewSupervisor returns a Supervisor for program, already attached to process
+// whose signatures have been scanned. With a nil process it attaches on the
+// first Poll, and keeps trying until D2R can be scanned.
+func NewSupervisor(program string, process utils.Process) *Supervisor {
+	return &Supervisor{
+		program:   program,
+		process:   process,
+		session:   NewSession(),
+		attach:    utils.AttachProcess,
+		lastCheck: time.Now(),
+	}
+}
+
+// Session returns the game session of the supervised process.
+func (s *Supervisor) Session() *Session {
+	return s.session
+}
+
+// Poll checks the attached process and reattaches after it exited. It returns
+// the process to read from, nil while D2R is not running, and whether it is a
+// different process than the one returned by the previous call.
+func (s *Supervisor) Poll() (utils.Process, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, false
+	}
+	now := time.Now()
+
+	if s.process != nil && now.Sub(s.lastCheck) >= handleCheckInterval {
+		s.lastCheck = now
+		if !s.process.IsHandleValid() {
+			log.Printf("D2R process %d exited, waiting for it to restart", s.process.ProcessID())
+			s.detach()
+			s.lastAttempt = now
+		}
+	}
+
+	if s.process == nil && now.Sub(s.lastAttempt) >= reattachInterval {
+		s.lastAttempt = now
+		s.reattach()
+	}
+
+	changed := s.changed
+	s.changed = false
+	return s.process, changed
+}
+
+// reattach looks for a new D2R process and resolves its signatures. A process
+// that was just started may not be scannable yet, it is retried on the next attempt.
+func (s *Supervisor) reattach() {
+	process, err := s.attach(s.program)
+	if err != nil {
+		attachLog.Printf("attach", "D2R is not running: %v", err)
+		return
+	}
+	if err := PatternScan(process, true); err != nil {
+		attachLog.Printf("scan", "Attached to D2R process %d but the signature scan failed: %v", process.ProcessID(), err)
+		utils.IfError(process.Close(), "Failed to close D2R process handle")
+		return
+	}
+
+	log.Printf("Attached to D2R process %d", process.ProcessID())
+	ResetTrackers()
+	s.process = process
+	s.lastCheck = time.Now()
+	s.changed = true
+}
+
+func (s *Supervisor) detach() {
+	s.session.Detach()
+	utils.IfError(s.process.Close(), "Failed to close D2R process handle")
+	s.process = nil
+	s.changed = true
+}
+
+// Close stops the session and releases the attached process, if any.
+func (s *Supervisor) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.session.Exit()
+	if s.process != nil {
+		utils.IfError(s.process.Close(), "Failed to close D2R process handle")
+		s.process = nil
+	}
+}
@@ -0,0 +1,84 @@
+// memory/supervisor_test.go
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GalyMap/globals"
+	"GalyMap/utils"
+)
+
+// fakeProcess is a Process serving reads from a module image
+type fakeProcess struct {
+	*utils.SnapshotReader
+	closed bool
+}
+
+func (fp *fakeProcess) ProcessID() uint32   { return 42 }
+func (fp *fakeProcess) IsHandleValid() bool { return !fp.closed }
+func (fp *fakeProcess) Close() error        { fp.closed = true; return nil }
+
+// scannableProcess returns a process whose module matches a signature table
+// resolving every required offset, and installs that table.
+func scannableProcess(t *testing.T) *fakeProcess {
+	globals.InitSettings()
+	dir := t.TempDir()
+	savedSignatures, savedCache := SignaturesFile, OffsetCacheFile
+	SignaturesFile, OffsetCacheFile = filepath.Join(dir, "signatures.yaml"), filepath.Join(dir, "offsets_cache.yaml")
+	t.Cleanup(func() { SignaturesFile, OffsetCacheFile = savedSignatures, savedCache })
+
+	module := testModule(0x1000)
+	var table strings.Builder
+	table.WriteString("signatures:\n")
+	for i, name := range requiredOffsets {
+		at := 0x400 + i*0x10
+		copy(module[at:], []byte{0xA0 + byte(i), 0xB1, 0xC2, 0xD3, byte(i), 0, 0, 0})
+		fmt.Fprintf(&table, "  - name: %s\n    pattern: \"%02X B1 C2 D3\"\n    count: 1\n    read: 4\n", name, 0xA0+i)
+	}
+	if err := os.WriteFile(SignaturesFile, []byte(table.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return &fakeProcess{SnapshotReader: utils.NewSnapshotReader(&utils.Snapshot{BaseAddress: 0x140000000, Module: module})}
+}
+
+func TestSupervisorAttachesLater(t *testing.T) {
+	process := scannableProcess(t)
+	unscannable := &fakeProcess{SnapshotReader: utils.NewSnapshotReader(&utils.Snapshot{BaseAddress: 0x140000000, Module: testModule(0x1000)})}
+
+	attach := []func() (utils.Process, error){
+		func() (utils.Process, error) { return nil, errors.New("process not found") },
+		func() (utils.Process, error) { return unscannable, nil },
+		func() (utils.Process, error) { return process, nil },
+	}
+
+	// Started without a process, as when the first scan failed
+	s := NewSupervisor("D2R.exe", nil)
+	defer s.Close()
+	for i, next := range attach {
+		s.attach = func(string) (utils.Process, error) { return next() }
+		s.lastAttempt = time.Time{}
+		got, changed := s.Poll()
+		if i < len(attach)-1 {
+			if got != nil || changed {
+				t.Errorf("attempt %d: Poll = %v, %v; want no process", i, got, changed)
+			}
+			continue
+		}
+		if got != utils.Process(process) || !changed {
+			t.Errorf("attempt %d: Poll = %v, %v; want the scanned process", i, got, changed)
+		}
+	}
+	if !unscannable.closed {
+		t.Errorf("the process that failed the scan wasn't closed")
+	}
+	if process.closed {
+		t.Errorf("the attached process was closed")
+	}
+}
@@ -0,0 +1,69 @@
+// memory/units_test.go
+
+package memory
+
+import (
+	"testing"
+
+	"GalyMap/utils"
+)
+
+const (
+	// testUnitTable is the offset of the unit hash table in the fake module image
+	testUnitTable = 0x400
+	// testHeap is the first address past the fake module image, where the units go
+	testHeap = utils.FakeBase + utils.FakeModuleSize
+)
+
+// testUnit is a unit to write into a fake process, with the structures it points to
+type testUnit struct {
+	address    uintptr
+	unitType   uint32
+	txtFileNo  uint32
+	unitID     uint32
+	mode       uint32
+	unitData   uintptr
+	path       uintptr
+	statList   uintptr
+	next       uintptr
+	corpseFlag uint8
+}
+
+// newUnitProcess maps the fake module image and size bytes of heap.
+func newUnitProcess(size int) *utils.FakeProcess {
+	return utils.NewFakeProcess(utils.FakeModuleSize + size)
+}
+
+// putUnit writes the UnitAny fields of u at u.address.
+func putUnit(fp *utils.FakeProcess, u testUnit) {
+	fp.PutUint32(u.address+0x00, u.unitType)
+	fp.PutUint32(u.address+0x04, u.txtFileNo)
+	fp.PutUint32(u.address+0x08, u.unitID)
+	fp.PutUint32(u.address+0x0C, u.mode)
+	fp.PutUint64(u.address+0x10, uint64(u.unitData))
+	fp.PutUint64(u.address+0x38, uint64(u.path))
+	fp.PutUint64(u.address+0x88, uint64(u.statList))
+	fp.PutUint64(u.address+0x150, uint64(u.next))
+	fp.Put(u.address+0x1A6, []byte{u.corpseFlag})
+}
+
+// putBucket points bucket of the unitType table at the first unit of a chain.
+func putBucket(fp *utils.FakeProcess, unitType, bucket int, address uintptr) {
+	fp.PutUint64(utils.FakeBase+testUnitTable+uintptr(unitType)*unitTableSize+uintptr(bucket)*8, uint64(address))
+}
+
+// putStaticPath writes the position of an object or an item.
+func putStaticPath(fp *utils.FakeProcess, address uintptr, x, y uint16) {
+	fp.PutUint16(address+0x10, x)
+	fp.PutUint16(address+0x14, y)
+}
+
+// heapAddress returns the address of the i-th 0x200 byte block of the heap, for laying out structures.
+func heapAddress(t *testing.T, fp *utils.FakeProcess, i int) uintptr {
+	t.Helper()
+	address := testHeap + uintptr(i)*0x200
+	if int(address-utils.FakeBase)+0x200 > len(fp.Memory) {
+		t.Fatalf("heap block %d is past the fake process memory", i)
+	}
+	return address
+}
@@ -0,0 +1,99 @@
+// memory/unitwalk.go
+package memory
+
+import (
+	"GalyMap/utils"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxUnitChainLength bounds the number of units followed in one hash bucket.
+// Real chains are a handful of units long; anything near this is garbage.
+const maxUnitChainLength = 1024
+
+// UnitChainError reports a malformed chain in the unit hash table
+type UnitChainError struct {
+	UnitType int
+	Bucket   int
+	Address  uintptr // unit at which the walk stopped
+	Reason   string
+	Err      error
+}
+
+func (e *UnitChainError) Error() string {
+	msg := fmt.Sprintf("unit table %d bucket %d: %s at 0x%X", e.UnitType, e.Bucket, e.Reason, e.Address)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *UnitChainError) Unwrap() error {
+	return e.Err
+}
+
+// WalkUnits calls visit for every unit of unitType in the unit hash table at
+// tableOffset from the module base, following each bucket's chain of Next
+// pointers. The walk stops early when visit returns false.
+//
+// A chain ends at a null Next pointer or one pointing back at the same unit.
+// A bucket whose chain can't be read, loops back on itself or runs longer
+// than maxUnitChainLength is abandoned and reported in the returned error,
+// while the remaining buckets are still walked.
+func WalkUnits(d2r utils.ProcessReader, tableOffset uintptr, unitType int, visit func(address uintptr, unit UnitAny) bool) error {
+	tableAddress := d2r.BaseAddress() + tableOffset + uintptr(unitType)*unitTableSize
+	unitTableBuffer, err := d2r.ReadRaw(tableAddress, unitTableSize)
+	if err != nil {
+		return fmt.Errorf("failed to read unit table %d: %w", unitType, err)
+	}
+	if len(unitTableBuffer) < unitTableSize {
+		return fmt.Errorf("short read of unit table %d", unitType)
+	}
+
+	var errs []error
+	seen := make(map[uint64]bool)
+	for bucket := 0; bucket < 128; bucket++ {
+		address := binary.LittleEndian.Uint64(unitTableBuffer[bucket*8:])
+		chainError := func(reason string, err error) {
+			errs = append(errs, &UnitChainError{UnitType: unitType, Bucket: bucket, Address: uintptr(address), Reason: reason, Err: err})
+		}
+
+		for length := 0; address != 0; length++ {
+			if length == maxUnitChainLength {
+				chainError("chain too long", nil)
+				break
+			}
+			if seen[address] {
+				chainError("unit already visited (cycle)", nil)
+				break
+			}
+			seen[address] = true
+
+			unit, err := utils.ReadStruct[UnitAny](d2r, uintptr(address))
+			if err != nil {
+				chainError("unreadable unit", err)
+				break
+			}
+			if !visit(uintptr(address), unit) {
+				return errors.Join(errs...)
+			}
+			if unit.Next == address {
+				// A unit pointing at itself also ends the chain
+				break
+			}
+			address = unit.Next
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CollectUnits returns every unit of unitType, see WalkUnits.
+func CollectUnits(d2r utils.ProcessReader, tableOffset uintptr, unitType int) ([]UnitAny, error) {
+	var units []UnitAny
+	err := WalkUnits(d2r, tableOffset, unitType, func(_ uintptr, unit UnitAny) bool {
+		units = append(units, unit)
+		return true
+	})
+	return units, err
+}
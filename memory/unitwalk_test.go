@@ -0,0 +1,131 @@
+// memory/unitwalk_test.go
+
+package memory
+
+import (
+	"errors"
+	"testing"
+
+	"GalyMap/utils"
+)
+
+func TestWalkUnits(t *testing.T) {
+	const unmapped = utils.FakeBase + 0x10_0000_0000
+	tests := []struct {
+		name string
+		// chain returns the units of bucket 0 in order and the Next pointer of the last one,
+		// given the address of the i-th unit
+		chain      func(unit func(i int) uintptr) (length int, last uintptr)
+		wantVisits int
+		wantReason string // of the UnitChainError, "" for none
+	}{
+		{"null terminated", func(unit func(int) uintptr) (int, uintptr) { return 3, 0 }, 3, ""},
+		{"self pointing", func(unit func(int) uintptr) (int, uintptr) { return 1, unit(0) }, 1, ""},
+		{"self pointing at the end", func(unit func(int) uintptr) (int, uintptr) { return 3, unit(2) }, 3, ""},
+		{"two node cycle", func(unit func(int) uintptr) (int, uintptr) { return 2, unit(0) }, 2, "unit already visited (cycle)"},
+		{"cycle into the middle", func(unit func(int) uintptr) (int, uintptr) { return 4, unit(1) }, 4, "unit already visited (cycle)"},
+		{"too long", func(unit func(int) uintptr) (int, uintptr) { return maxUnitChainLength + 5, 0 }, maxUnitChainLength, "chain too long"},
+		{"unreadable next", func(unit func(int) uintptr) (int, uintptr) { return 2, unmapped }, 2, "unreadable unit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := newUnitProcess((maxUnitChainLength + 8) * 0x200)
+			unit := func(i int) uintptr { return heapAddress(t, fp, i) }
+
+			length, last := tt.chain(unit)
+			for i := 0; i < length; i++ {
+				next := last
+				if i < length-1 {
+					next = unit(i + 1)
+				}
+				putUnit(fp, testUnit{address: unit(i), unitType: unitMonster, unitID: uint32(i), next: next})
+			}
+			putBucket(fp, unitMonster, 0, unit(0))
+			// A bucket after a malformed one is still walked
+			other := unit(maxUnitChainLength + 6)
+			putUnit(fp, testUnit{address: other, unitType: unitMonster, unitID: 9999})
+			putBucket(fp, unitMonster, 5, other)
+
+			visits := 0
+			sawOther := false
+			err := WalkUnits(fp, testUnitTable, unitMonster, func(address uintptr, u UnitAny) bool {
+				visits++
+				sawOther = sawOther || u.UnitId == 9999
+				return true
+			})
+			if !sawOther {
+				t.Errorf("the unit of the next bucket wasn't visited")
+			}
+			if visits-1 != tt.wantVisits {
+				t.Errorf("visited %d units of the chain, want %d", visits-1, tt.wantVisits)
+			}
+
+			var chainErr *UnitChainError
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Errorf("WalkUnits = %v, want no error", err)
+				}
+				return
+			}
+			if !errors.As(err, &chainErr) {
+				t.Fatalf("WalkUnits = %v, want a UnitChainError", err)
+			}
+			if chainErr.Reason != tt.wantReason || chainErr.UnitType != unitMonster || chainErr.Bucket != 0 {
+				t.Errorf("UnitChainError = %+v, want %q in bucket 0", chainErr, tt.wantReason)
+			}
+			if tt.wantReason == "unreadable unit" && (!errors.Is(err, utils.ErrReadFault) || chainErr.Address != unmapped) {
+				t.Errorf("UnitChainError = %v, want a read fault at 0x%X", err, unmapped)
+			}
+		})
+	}
+}
+
+func TestWalkUnitsStops(t *testing.T) {
+	fp := newUnitProcess(0x1000)
+	unit := func(i int) uintptr { return heapAddress(t, fp, i) }
+	putUnit(fp, testUnit{address: unit(0), unitType: unitItem, next: unit(1)})
+	putUnit(fp, testUnit{address: unit(1), unitType: unitItem, next: unit(0)})
+	putBucket(fp, unitItem, 0, unit(0))
+
+	// The cycle isn't reached when visit stops the walk first
+	visits := 0
+	if err := WalkUnits(fp, testUnitTable, unitItem, func(uintptr, UnitAny) bool { visits++; return false }); err != nil || visits != 1 {
+		t.Errorf("WalkUnits = %v after %d visits, want no error after 1", err, visits)
+	}
+
+	// CollectUnits returns the units before the cycle along with its error
+	units, err := CollectUnits(fp, testUnitTable, unitItem)
+	if len(units) != 2 || err == nil {
+		t.Errorf("CollectUnits = %d units, %v; want 2 and the cycle", len(units), err)
+	}
+
+	// An unreadable table is an error of its own
+	if err := WalkUnits(fp, 0x10_0000, unitItem, func(uintptr, UnitAny) bool { return true }); err == nil {
+		t.Errorf("WalkUnits of an unmapped table succeeded")
+	}
+}
+
+// TestReadObjectsSkipsOtherUnits checks the chain that baseline ReadObjects
+// spun on forever: a unit that isn't an object in the object table, which
+// the loop skipped without moving to the next unit.
+func TestReadObjectsSkipsOtherUnits(t *testing.T) {
+	fp := newUnitProcess(0x1000)
+	block := func(i int) uintptr { return heapAddress(t, fp, i) }
+	putUnit(fp, testUnit{address: block(0), unitType: unitMonster, txtFileNo: 2, next: block(1)})
+	putUnit(fp, testUnit{address: block(1), unitType: unitObject, txtFileNo: 2, unitID: 4, unitData: block(2), path: block(3), next: block(4)})
+	putUnit(fp, testUnit{address: block(4), unitType: unitMissile, next: block(0)})
+	putStaticPath(fp, block(3), 5100, 5200)
+	putBucket(fp, unitObject, 0, block(0))
+
+	objects, err := ReadObjects(fp, testUnitTable, 0, 2)
+	if len(objects) != 1 || objects[0].UnitID != 4 {
+		t.Errorf("ReadObjects = %+v, want the shrine alone", objects)
+	}
+	var chainErr *UnitChainError
+	if !errors.As(err, &chainErr) || chainErr.Reason != "unit already visited (cycle)" {
+		t.Errorf("ReadObjects = %v, want the cycle back to the first unit", err)
+	}
+	if fp.Reads > 10 {
+		t.Errorf("ReadObjects made %d reads for a chain of 3 units", fp.Reads)
+	}
+}
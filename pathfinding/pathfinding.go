@@ -0,0 +1,272 @@
+// pathfinding/pathfinding.go
+
+package pathfinding
+
+import (
+	"container/heap"
+	"errors"
+	"image"
+	"math"
+)
+
+// ErrNoPath is returned when the destination can't be reached from the start
+var ErrNoPath = errors.New("no path to destination")
+
+// Grid is a collision grid: At reports whether a cell is walkable, and is
+// false for every cell outside of Size.
+type Grid interface {
+	At(x, y int) bool
+	Size() (width, height int)
+}
+
+const (
+	// startSnapRadius is how far a blocked start is moved to the nearest walkable cell
+	startSnapRadius = 5
+	// goalSnapRadius is how far a blocked goal is moved, exits and objects usually sit on blocked cells
+	goalSnapRadius = 20
+	// teleportDirections is the number of directions tried from every teleport landing
+	teleportDirections = 32
+)
+
+// Options configures a search
+type Options struct {
+	// TeleportRange is the reach of one teleport in cells, zero to walk
+	TeleportRange int
+	// MaxNodes bounds the number of cells expanded, zero for no limit
+	MaxNodes int
+}
+
+// FindPath returns a route from start to goal over grid, including both ends.
+// Walking routes move between the 8 neighbouring cells without cutting
+// corners and are reduced to the cells where they turn. Teleport routes are
+// the landing cells of the fewest teleports that reach the goal, ignoring walls
+// in between as teleport does.
+func FindPath(grid Grid, start, goal image.Point, opts Options) ([]image.Point, error) {
+	start, ok := nearestWalkable(grid, start, startSnapRadius)
+	if !ok {
+		return nil, errors.New("start is not walkable")
+	}
+	goal, ok = nearestWalkable(grid, goal, goalSnapRadius)
+	if !ok {
+		return nil, errors.New("destination is not walkable")
+	}
+	if start == goal {
+		return []image.Point{start}, nil
+	}
+
+	s := newSearch(grid, start, goal, opts.MaxNodes)
+	if opts.TeleportRange > 0 {
+		return s.run(teleportNeighbours(grid, goal, opts.TeleportRange), func(p image.Point) float64 {
+			return math.Ceil(distance(p, goal) / float64(opts.TeleportRange))
+		})
+	}
+	path, err := s.run(walkNeighbours(grid), func(p image.Point) float64 {
+		return octile(p, goal)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Simplify(path), nil
+}
+
+// Simplify drops the points in the middle of straight runs of path.
+func Simplify(path []image.Point) []image.Point {
+	if len(path) < 3 {
+		return path
+	}
+	simplified := []image.Point{path[0]}
+	for i := 1; i < len(path)-1; i++ {
+		if path[i].Sub(path[i-1]) != path[i+1].Sub(path[i]) {
+			simplified = append(simplified, path[i])
+		}
+	}
+	return append(simplified, path[len(path)-1])
+}
+
+// Length returns the length of path in cells.
+func Length(path []image.Point) float64 {
+	length := 0.0
+	for i := 1; i < len(path); i++ {
+		length += distance(path[i-1], path[i])
+	}
+	return length
+}
+
+// nearestWalkable returns p if it is walkable, otherwise the closest walkable cell within radius.
+func nearestWalkable(grid Grid, p image.Point, radius int) (image.Point, bool) {
+	if grid.At(p.X, p.Y) {
+		return p, true
+	}
+	best, bestDistance := p, math.MaxInt
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if d := dx*dx + dy*dy; d < bestDistance && d <= radius*radius && grid.At(p.X+dx, p.Y+dy) {
+				best, bestDistance = image.Point{X: p.X + dx, Y: p.Y + dy}, d
+			}
+		}
+	}
+	return best, bestDistance != math.MaxInt
+}
+
+// neighbours calls visit with every cell reachable in one step from p and the cost of the step
+type neighbours func(p image.Point, visit func(next image.Point, cost float64))
+
+var directions = [8]image.Point{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func walkNeighbours(grid Grid) neighbours {
+	return func(p image.Point, visit func(image.Point, float64)) {
+		for _, d := range directions {
+			next := p.Add(d)
+			if !grid.At(next.X, next.Y) {
+				continue
+			}
+			if d.X != 0 && d.Y != 0 {
+				// Moving diagonally past a wall corner isn't possible in game
+				if !grid.At(p.X+d.X, p.Y) || !grid.At(p.X, p.Y+d.Y) {
+					continue
+				}
+				visit(next, math.Sqrt2)
+			} else {
+				visit(next, 1)
+			}
+		}
+	}
+}
+
+func teleportNeighbours(grid Grid, goal image.Point, teleportRange int) neighbours {
+	return func(p image.Point, visit func(image.Point, float64)) {
+		if distance(p, goal) <= float64(teleportRange) {
+			visit(goal, 1)
+			return
+		}
+		for i := 0; i < teleportDirections; i++ {
+			angle := 2 * math.Pi * float64(i) / teleportDirections
+			dx, dy := math.Cos(angle), math.Sin(angle)
+			// Land as far as possible along the direction, falling short of blocked cells
+			for reach := teleportRange; reach >= teleportRange/2 && reach > 0; reach-- {
+				next := image.Point{X: p.X + int(math.Round(dx*float64(reach))), Y: p.Y + int(math.Round(dy*float64(reach)))}
+				if grid.At(next.X, next.Y) {
+					visit(next, 1)
+					break
+				}
+			}
+		}
+	}
+}
+
+// search holds the per-cell state of one A* run in flat arrays indexed by cell
+type search struct {
+	width, height int
+	start, goal   image.Point
+	cost          []float64
+	parent        []int32
+	closed        []bool
+	maxNodes      int
+}
+
+func newSearch(grid Grid, start, goal image.Point, maxNodes int) *search {
+	width, height := grid.Size()
+	s := &search{
+		width:    width,
+		height:   height,
+		start:    start,
+		goal:     goal,
+		cost:     make([]float64, width*height),
+		parent:   make([]int32, width*height),
+		closed:   make([]bool, width*height),
+		maxNodes: maxNodes,
+	}
+	for i := range s.cost {
+		s.cost[i] = math.Inf(1)
+		s.parent[i] = -1
+	}
+	return s
+}
+
+func (s *search) index(p image.Point) int {
+	return p.Y*s.width + p.X
+}
+
+func (s *search) point(i int) image.Point {
+	return image.Point{X: i % s.width, Y: i / s.width}
+}
+
+func (s *search) run(next neighbours, heuristic func(image.Point) float64) ([]image.Point, error) {
+	open := &openSet{}
+	startIndex := s.index(s.start)
+	s.cost[startIndex] = 0
+	heap.Push(open, openNode{index: startIndex, priority: heuristic(s.start)})
+
+	expanded := 0
+	goalIndex := s.index(s.goal)
+	for open.Len() > 0 {
+		current := heap.Pop(open).(openNode)
+		if s.closed[current.index] {
+			continue
+		}
+		if current.index == goalIndex {
+			return s.path(goalIndex), nil
+		}
+		s.closed[current.index] = true
+		if expanded++; s.maxNodes > 0 && expanded > s.maxNodes {
+			break
+		}
+
+		p := s.point(current.index)
+		next(p, func(n image.Point, stepCost float64) {
+			if n.X < 0 || n.Y < 0 || n.X >= s.width || n.Y >= s.height {
+				return
+			}
+			i := s.index(n)
+			if s.closed[i] {
+				return
+			}
+			if cost := s.cost[current.index] + stepCost; cost < s.cost[i] {
+				s.cost[i] = cost
+				s.parent[i] = int32(current.index)
+				heap.Push(open, openNode{index: i, priority: cost + heuristic(n)})
+			}
+		})
+	}
+	return nil, ErrNoPath
+}
+
+func (s *search) path(goalIndex int) []image.Point {
+	var path []image.Point
+	for i := goalIndex; i != -1; i = int(s.parent[i]) {
+		path = append(path, s.point(i))
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+type openNode struct {
+	index    int
+	priority float64
+}
+
+// openSet is a min-heap of cells to expand ordered by estimated total cost
+type openSet []openNode
+
+func (o openSet) Len() int           { return len(o) }
+func (o openSet) Less(i, j int) bool { return o[i].priority < o[j].priority }
+func (o openSet) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o *openSet) Push(x any)        { *o = append(*o, x.(openNode)) }
+func (o *openSet) Pop() any {
+	old := *o
+	n := old[len(old)-1]
+	*o = old[:len(old)-1]
+	return n
+}
+
+func distance(a, b image.Point) float64 {
+	return math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+}
+
+// octile is the walking distance between a and b on an open 8-connected grid
+func octile(a, b image.Point) float64 {
+	dx, dy := math.Abs(float64(a.X-b.X)), math.Abs(float64(a.Y-b.Y))
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
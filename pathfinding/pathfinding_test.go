@@ -0,0 +1,171 @@
+// pathfinding/pathfinding_test.go
+
+package pathfinding
+
+import (
+	"errors"
+	"image"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// asciiGrid is a Grid drawn with '#' for blocked cells, '.' for walkable ones
+// and 'S' and 'G' for the walkable start and goal
+type asciiGrid struct {
+	rows        []string
+	start, goal image.Point
+}
+
+func parseGrid(t *testing.T, text string) *asciiGrid {
+	t.Helper()
+	g := &asciiGrid{rows: strings.Fields(text)}
+	for y, row := range g.rows {
+		if len(row) != len(g.rows[0]) {
+			t.Fatalf("row %d is %d cells wide, want %d", y, len(row), len(g.rows[0]))
+		}
+		if x := strings.IndexByte(row, 'S'); x >= 0 {
+			g.start = image.Pt(x, y)
+		}
+		if x := strings.IndexByte(row, 'G'); x >= 0 {
+			g.goal = image.Pt(x, y)
+		}
+	}
+	return g
+}
+
+func (g *asciiGrid) At(x, y int) bool {
+	return y >= 0 && y < len(g.rows) && x >= 0 && x < len(g.rows[y]) && g.rows[y][x] != '#'
+}
+
+func (g *asciiGrid) Size() (int, int) {
+	return len(g.rows[0]), len(g.rows)
+}
+
+func TestFindPathWalking(t *testing.T) {
+	tests := []struct {
+		name   string
+		grid   string
+		want   []image.Point // nil when there are several shortest paths
+		length float64
+	}{
+		{"straight", `
+			..........
+			.S......G.
+			..........`,
+			[]image.Point{{1, 1}, {8, 1}}, 7},
+		{"diagonal", `
+			S....
+			.....
+			....G`,
+			nil, 2 + 2*math.Sqrt2},
+		{"no corner cutting", `
+			.....
+			.S#..
+			..G..`,
+			[]image.Point{{1, 1}, {1, 2}, {2, 2}}, 2},
+		{"around a wall end", `
+			S.#..
+			..#..
+			..#G.
+			.....`,
+			[]image.Point{{0, 0}, {1, 1}, {1, 3}, {3, 3}, {3, 2}}, 5 + math.Sqrt2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := parseGrid(t, tt.grid)
+			path, err := FindPath(g, g.start, g.goal, Options{})
+			if err != nil {
+				t.Fatalf("FindPath: %v", err)
+			}
+			if math.Abs(Length(path)-tt.length) > 1e-9 {
+				t.Errorf("path %v is %.3f cells long, want %.3f", path, Length(path), tt.length)
+			}
+			if tt.want != nil && !reflect.DeepEqual(path, tt.want) {
+				t.Errorf("path = %v, want %v", path, tt.want)
+			}
+			checkWalkable(t, g, path)
+		})
+	}
+}
+
+// checkWalkable fails if a straight run of path crosses a blocked cell or cuts a wall corner.
+func checkWalkable(t *testing.T, g *asciiGrid, path []image.Point) {
+	t.Helper()
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		step := image.Pt(sign(to.X-from.X), sign(to.Y-from.Y))
+		for p := from; p != to; p = p.Add(step) {
+			next := p.Add(step)
+			if !g.At(next.X, next.Y) || !g.At(p.X+step.X, p.Y) || !g.At(p.X, p.Y+step.Y) {
+				t.Errorf("step from %v to %v crosses a wall", p, next)
+				return
+			}
+		}
+	}
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestFindPathUnreachable(t *testing.T) {
+	for name, grid := range map[string]string{
+		"walled room": `
+			S..#####
+			...#.G.#
+			...#####`,
+		"diagonal gap": `
+			S#
+			#G`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := parseGrid(t, grid)
+			if path, err := FindPath(g, g.start, g.goal, Options{}); !errors.Is(err, ErrNoPath) {
+				t.Errorf("FindPath = %v, %v; want ErrNoPath", path, err)
+			}
+		})
+	}
+}
+
+func TestFindPathTeleport(t *testing.T) {
+	// A wall that can't be walked around, but can be teleported over
+	g := parseGrid(t, `
+		S..............#.......................G`)
+
+	if _, err := FindPath(g, g.start, g.goal, Options{}); !errors.Is(err, ErrNoPath) {
+		t.Fatalf("walking through the wall: %v, want ErrNoPath", err)
+	}
+	for _, tt := range []struct {
+		teleportRange int
+		landings      int
+	}{
+		{10, 5},
+		{20, 3},
+		{39, 2},
+		{7, 7},
+	} {
+		path, err := FindPath(g, g.start, g.goal, Options{TeleportRange: tt.teleportRange})
+		if err != nil {
+			t.Fatalf("range %d: FindPath: %v", tt.teleportRange, err)
+		}
+		if len(path) != tt.landings || path[0] != g.start || path[len(path)-1] != g.goal {
+			t.Errorf("range %d: path = %v, want %d landings from %v to %v", tt.teleportRange, path, tt.landings, g.start, g.goal)
+		}
+		for i := 1; i < len(path); i++ {
+			if d := distance(path[i-1], path[i]); d > float64(tt.teleportRange) {
+				t.Errorf("range %d: teleport from %v to %v is %.1f cells", tt.teleportRange, path[i-1], path[i], d)
+			}
+			if !g.At(path[i].X, path[i].Y) {
+				t.Errorf("range %d: landed on blocked cell %v", tt.teleportRange, path[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// render/atlas.go
+
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"sort"
+)
+
+const (
+	// atlasWidth is the width of packed atlases; they grow downwards as needed
+	atlasWidth = 1024
+	// atlasPadding keeps neighbouring cells from bleeding into each other when
+	// sampled; each cell's edge pixels are extruded a pixel into it
+	atlasPadding = 2
+)
+
+// Atlas is a set of named images packed into one texture
+type Atlas struct {
+	Image *image.NRGBA
+	cells []image.Rectangle
+	names map[string]int
+}
+
+// Index returns the index of the named cell.
+func (a *Atlas) Index(name string) (int, bool) {
+	i, ok := a.names[name]
+	return i, ok
+}
+
+// Cell returns the pixel bounds of cell i in the atlas image.
+func (a *Atlas) Cell(i int) image.Rectangle {
+	return a.cells[i]
+}
+
+// Len returns the number of cells.
+func (a *Atlas) Len() int {
+	return len(a.cells)
+}
+
+// UV returns the texture coordinates of cell i as u0, v0, u1, v1, with v0 at
+// the top row of the image.
+func (a *Atlas) UV(i int) [4]float32 {
+	cell := a.cells[i]
+	w, h := float32(a.Image.Bounds().Dx()), float32(a.Image.Bounds().Dy())
+	return [4]float32{float32(cell.Min.X) / w, float32(cell.Min.Y) / h, float32(cell.Max.X) / w, float32(cell.Max.Y) / h}
+}
+
+// AtlasBuilder collects images and packs them into an Atlas
+type AtlasBuilder struct {
+	names  []string
+	images []image.Image
+}
+
+// Add queues img under name, replacing an image added before under the same name.
+func (b *AtlasBuilder) Add(name string, img image.Image) {
+	for i, existing := range b.names {
+		if existing == name {
+			b.images[i] = img
+			return
+		}
+	}
+	b.names = append(b.names, name)
+	b.images = append(b.images, img)
+}
+
+// Has reports whether an image was added under name.
+func (b *AtlasBuilder) Has(name string) bool {
+	for _, existing := range b.names {
+		if existing == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Build packs the images into shelves, tallest first, and returns the atlas.
+// Cell indices follow the order in which the images were added.
+func (b *AtlasBuilder) Build() (*Atlas, error) {
+	order := make([]int, len(b.images))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return b.images[order[i]].Bounds().Dy() > b.images[order[j]].Bounds().Dy()
+	})
+
+	cells := make([]image.Rectangle, len(b.images))
+	x, y, shelfHeight := 0, 0, 0
+	for _, i := range order {
+		size := b.images[i].Bounds().Size()
+		if size.X+atlasPadding > atlasWidth {
+			return nil, fmt.Errorf("atlas image %s is wider than %d pixels", b.names[i], atlasWidth-atlasPadding)
+		}
+		if x+size.X+atlasPadding > atlasWidth {
+			x, y, shelfHeight = 0, y+shelfHeight, 0
+		}
+		cells[i] = image.Rectangle{Min: image.Point{X: x + atlasPadding, Y: y + atlasPadding}, Max: image.Point{X: x + atlasPadding + size.X, Y: y + atlasPadding + size.Y}}
+		x += size.X + atlasPadding
+		shelfHeight = max(shelfHeight, size.Y+atlasPadding)
+	}
+
+	atlas := &Atlas{
+		Image: image.NewNRGBA(image.Rect(0, 0, atlasWidth, max(y+shelfHeight+atlasPadding, 1))),
+		cells: cells,
+		names: make(map[string]int, len(b.names)),
+	}
+	for i, img := range b.images {
+		draw.Draw(atlas.Image, cells[i], img, img.Bounds().Min, draw.Src)
+		extrude(atlas.Image, cells[i])
+		atlas.names[b.names[i]] = i
+	}
+	return atlas, nil
+}
+
+// extrude copies the edge pixels of cell a pixel outwards, so that filtered
+// sampling at the edge of a stretched cell doesn't fade into the padding.
+func extrude(img *image.NRGBA, cell image.Rectangle) {
+	if cell.Empty() {
+		return
+	}
+	for y := cell.Min.Y; y < cell.Max.Y; y++ {
+		img.SetNRGBA(cell.Min.X-1, y, img.NRGBAAt(cell.Min.X, y))
+		img.SetNRGBA(cell.Max.X, y, img.NRGBAAt(cell.Max.X-1, y))
+	}
+	for x := cell.Min.X - 1; x <= cell.Max.X; x++ {
+		img.SetNRGBA(x, cell.Min.Y-1, img.NRGBAAt(x, cell.Min.Y))
+		img.SetNRGBA(x, cell.Max.Y, img.NRGBAAt(x, cell.Max.Y-1))
+	}
+}
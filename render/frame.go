@@ -0,0 +1,66 @@
+// render/frame.go
+
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// Instance is one textured quad of a frame, in overlay pixels
+type Instance struct {
+	X, Y          float32 // center
+	Width, Height float32
+	Rotation      float32     // radians, clockwise on screen
+	Color         color.NRGBA // multiplied with the atlas cell
+	Cell          int         // index of the atlas cell
+}
+
+// Layer is an image drawn under the instances, stretched onto a quad
+type Layer struct {
+	Image   *image.NRGBA
+	Corners [4][2]float32   // overlay pixels of the top left, top right, bottom right and bottom left corners
+	Clip    image.Rectangle // overlay pixels the layer is limited to, unlimited when empty
+}
+
+// Frame is everything drawn in one frame: the layers, then the instances from the atlas, in drawing order
+type Frame struct {
+	Atlas     *Atlas
+	Layers    []Layer
+	Instances []Instance
+}
+
+// NewFrame returns an empty frame drawing from atlas.
+func NewFrame(atlas *Atlas) *Frame {
+	return &Frame{Atlas: atlas}
+}
+
+// Reset empties the frame, keeping its storage for the next one.
+func (f *Frame) Reset() {
+	f.Layers = f.Layers[:0]
+	f.Instances = f.Instances[:0]
+}
+
+// AddLayer queues img stretched onto the quad with the given corners, cut to clip unless it is empty.
+func (f *Frame) AddLayer(img *image.NRGBA, corners [4][2]float32, clip image.Rectangle) {
+	f.Layers = append(f.Layers, Layer{Image: img, Corners: corners, Clip: clip})
+}
+
+// Add queues the atlas cell named cell centered on x, y. Unknown cells are skipped.
+func (f *Frame) Add(cell string, x, y, w, h float32, c color.NRGBA) {
+	f.AddRotated(cell, x, y, w, h, 0, c)
+}
+
+// AddRotated queues the atlas cell named cell centered on x, y and rotated by angle radians.
+func (f *Frame) AddRotated(cell string, x, y, w, h, angle float32, c color.NRGBA) {
+	index, ok := f.Atlas.Index(cell)
+	if !ok {
+		return
+	}
+	f.Instances = append(f.Instances, Instance{X: x, Y: y, Width: w, Height: h, Rotation: angle, Color: c, Cell: index})
+}
+
+// Renderer draws frames
+type Renderer interface {
+	Render(frame *Frame)
+}
@@ -0,0 +1,97 @@
+// render/shapes.go
+
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Shapes are white atlas cells with a dark outline, tinted by the instance color
+const (
+	ShapeSquare  = "square"
+	ShapeDot     = "dot"
+	ShapeDiamond = "diamond"
+	ShapeRing    = "ring"
+	ShapeCross   = "cross"
+	ShapeArrow   = "arrow" // points right
+)
+
+// CellSolid is a plain white cell for filled rectangles, such as panel backgrounds
+const CellSolid = "solid"
+
+const (
+	shapeSize    = 16
+	shapeOutline = 1.5
+)
+
+var (
+	shapeFill         = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	shapeOutlineColor = color.NRGBA{A: 200}
+)
+
+// shapeDistance returns how far inside shape the point x, y of a unit square
+// centered on the origin is, negative outside, in units of the half size
+var shapeDistance = map[string]func(x, y float64) float64{
+	ShapeSquare: func(x, y float64) float64 {
+		return 1 - math.Max(math.Abs(x), math.Abs(y))
+	},
+	ShapeDot: func(x, y float64) float64 {
+		return 1 - math.Hypot(x, y)
+	},
+	ShapeDiamond: func(x, y float64) float64 {
+		return (1 - math.Abs(x) - math.Abs(y)) / math.Sqrt2
+	},
+	ShapeRing: func(x, y float64) float64 {
+		r := math.Hypot(x, y)
+		return math.Min(1-r, r-0.55)
+	},
+	ShapeCross: func(x, y float64) float64 {
+		// Two diagonal bars
+		bar := func(a, b float64) float64 {
+			return math.Min(0.3-math.Abs(a-b)/math.Sqrt2, 1-math.Max(math.Abs(a), math.Abs(b)))
+		}
+		return math.Max(bar(x, y), bar(x, -y))
+	},
+	ShapeArrow: func(x, y float64) float64 {
+		// Triangle from the left edge to the tip at the right edge
+		return math.Min(x+1, ((1-x)/2-math.Abs(y))/math.Sqrt(1.25))
+	},
+}
+
+// Shapes returns the names of all shapes.
+func Shapes() []string {
+	return []string{ShapeSquare, ShapeDot, ShapeDiamond, ShapeRing, ShapeCross, ShapeArrow}
+}
+
+// ShapeImage draws shape in white with a dark outline.
+func ShapeImage(shape string) *image.NRGBA {
+	distance := shapeDistance[shape]
+	img := image.NewNRGBA(image.Rect(0, 0, shapeSize, shapeSize))
+	half := float64(shapeSize) / 2
+	outline := shapeOutline / half
+	for y := 0; y < shapeSize; y++ {
+		for x := 0; x < shapeSize; x++ {
+			d := distance((float64(x)+0.5-half)/half, (float64(y)+0.5-half)/half)
+			switch {
+			case d >= outline:
+				img.SetNRGBA(x, y, shapeFill)
+			case d >= 0:
+				img.SetNRGBA(x, y, shapeOutlineColor)
+			}
+		}
+	}
+	return img
+}
+
+// AddShapes adds every shape to b under its name, along with CellSolid.
+func AddShapes(b *AtlasBuilder) {
+	for _, shape := range Shapes() {
+		b.Add(shape, ShapeImage(shape))
+	}
+	solid := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(solid, solid.Bounds(), image.NewUniform(shapeFill), image.Point{}, draw.Src)
+	b.Add(CellSolid, solid)
+}
@@ -0,0 +1,153 @@
+// render/software.go
+
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// SoftwareRenderer draws frames into an image on the CPU, the way the GL
+// renderer draws them on screen: layers sampled with the nearest texel,
+// atlas cells sampled bilinearly and tinted, all blended over the image.
+type SoftwareRenderer struct {
+	Image      *image.RGBA
+	Background color.RGBA // the image is cleared to it before each frame
+}
+
+// NewSoftwareRenderer returns a renderer drawing into a transparent width by height image.
+func NewSoftwareRenderer(width, height int) *SoftwareRenderer {
+	return &SoftwareRenderer{Image: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+// Render clears the image and draws frame into it.
+func (r *SoftwareRenderer) Render(frame *Frame) {
+	draw.Draw(r.Image, r.Image.Bounds(), image.NewUniform(r.Background), image.Point{}, draw.Src)
+	for _, layer := range frame.Layers {
+		r.drawLayer(layer)
+	}
+	for _, instance := range frame.Instances {
+		r.drawInstance(frame.Atlas, instance)
+	}
+}
+
+// drawLayer maps every pixel covered by the layer's quad back onto its image.
+// The quad is the image through an affine transform, so three corners define it.
+func (r *SoftwareRenderer) drawLayer(layer Layer) {
+	img := layer.Image
+	size := img.Bounds().Size()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+	origin := layer.Corners[0]
+	ux, uy := layer.Corners[1][0]-origin[0], layer.Corners[1][1]-origin[1] // along the image width
+	vx, vy := layer.Corners[3][0]-origin[0], layer.Corners[3][1]-origin[1] // along the image height
+	det := ux*vy - uy*vx
+	if det == 0 {
+		return
+	}
+
+	bounds := r.quadBounds(layer.Corners)
+	if !layer.Clip.Empty() {
+		bounds = bounds.Intersect(layer.Clip)
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := float32(x)+0.5-origin[0], float32(y)+0.5-origin[1]
+			u := (px*vy - py*vx) / det
+			v := (ux*py - uy*px) / det
+			if u < 0 || u >= 1 || v < 0 || v >= 1 {
+				continue
+			}
+			c := img.NRGBAAt(img.Rect.Min.X+int(u*float32(size.X)), img.Rect.Min.Y+int(v*float32(size.Y)))
+			r.blend(x, y, c, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+	}
+}
+
+// drawInstance maps every pixel covered by the rotated instance back into its atlas cell.
+func (r *SoftwareRenderer) drawInstance(atlas *Atlas, instance Instance) {
+	if instance.Width == 0 || instance.Height == 0 {
+		return
+	}
+	cell := atlas.Cell(instance.Cell)
+	sin, cos := math.Sincos(float64(instance.Rotation))
+	s, c := float32(sin), float32(cos)
+
+	var corners [4][2]float32
+	for i, corner := range [4][2]float32{{-0.5, -0.5}, {0.5, -0.5}, {0.5, 0.5}, {-0.5, 0.5}} {
+		ox, oy := corner[0]*instance.Width, corner[1]*instance.Height
+		corners[i] = [2]float32{instance.X + ox*c - oy*s, instance.Y + ox*s + oy*c}
+	}
+
+	bounds := r.quadBounds(corners)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dx, dy := float32(x)+0.5-instance.X, float32(y)+0.5-instance.Y
+			u := (dx*c+dy*s)/instance.Width + 0.5
+			v := (-dx*s+dy*c)/instance.Height + 0.5
+			if u < 0 || u >= 1 || v < 0 || v >= 1 {
+				continue
+			}
+			sample := bilinear(atlas.Image, float32(cell.Min.X)+u*float32(cell.Dx()), float32(cell.Min.Y)+v*float32(cell.Dy()))
+			r.blend(x, y, sample, instance.Color)
+		}
+	}
+}
+
+// quadBounds returns the pixels of the image that corners may cover.
+func (r *SoftwareRenderer) quadBounds(corners [4][2]float32) image.Rectangle {
+	minX, minY := float32(math.Inf(1)), float32(math.Inf(1))
+	maxX, maxY := float32(math.Inf(-1)), float32(math.Inf(-1))
+	for _, corner := range corners {
+		minX, maxX = min(minX, corner[0]), max(maxX, corner[0])
+		minY, maxY = min(minY, corner[1]), max(maxY, corner[1])
+	}
+	bounds := image.Rect(int(math.Floor(float64(minX))), int(math.Floor(float64(minY))), int(math.Ceil(float64(maxX))), int(math.Ceil(float64(maxY))))
+	return bounds.Intersect(r.Image.Bounds())
+}
+
+// bilinear samples img at x, y in pixels, with texel centers at half pixels
+// and edges clamped, interpolating unpremultiplied colors like GL does.
+func bilinear(img *image.NRGBA, x, y float32) color.NRGBA {
+	x, y = x-0.5, y-0.5
+	x0, y0 := int(math.Floor(float64(x))), int(math.Floor(float64(y)))
+	fx, fy := x-float32(x0), y-float32(y0)
+	clamp := func(v, lo, hi int) int { return max(lo, min(hi-1, v)) }
+	b := img.Bounds()
+	at := func(px, py int) color.NRGBA {
+		return img.NRGBAAt(clamp(px, b.Min.X, b.Max.X), clamp(py, b.Min.Y, b.Max.Y))
+	}
+	c00, c10, c01, c11 := at(x0, y0), at(x0+1, y0), at(x0, y0+1), at(x0+1, y0+1)
+	mix := func(a, b, c, d uint8) uint8 {
+		top := float32(a)*(1-fx) + float32(b)*fx
+		bottom := float32(c)*(1-fx) + float32(d)*fx
+		return uint8(top*(1-fy) + bottom*fy + 0.5)
+	}
+	return color.NRGBA{
+		R: mix(c00.R, c10.R, c01.R, c11.R),
+		G: mix(c00.G, c10.G, c01.G, c11.G),
+		B: mix(c00.B, c10.B, c01.B, c11.B),
+		A: mix(c00.A, c10.A, c01.A, c11.A),
+	}
+}
+
+// blend draws sample tinted by tint over the pixel at x, y.
+func (r *SoftwareRenderer) blend(x, y int, sample, tint color.NRGBA) {
+	alpha := float32(sample.A) * float32(tint.A) / (255 * 255)
+	if alpha <= 0 {
+		return
+	}
+	i := r.Image.PixOffset(x, y)
+	pix := r.Image.Pix[i : i+4 : i+4]
+	for channel, value := range [3]float32{
+		float32(sample.R) * float32(tint.R) / 255,
+		float32(sample.G) * float32(tint.G) / 255,
+		float32(sample.B) * float32(tint.B) / 255,
+	} {
+		pix[channel] = uint8(value*alpha + float32(pix[channel])*(1-alpha) + 0.5)
+	}
+	pix[3] = uint8(255*alpha + float32(pix[3])*(1-alpha) + 0.5)
+}
@@ -0,0 +1,135 @@
+// render/sprites.go
+
+package render
+
+import (
+	"strings"
+
+	"GalyMap/globals"
+	"GalyMap/types"
+)
+
+// Sprite kinds, the names of the atlas cells entities are drawn with
+const (
+	SpriteMob         = "mob"
+	SpriteChampion    = "champion"
+	SpriteUnique      = "unique"
+	SpriteSuperUnique = "super_unique"
+	SpriteBoss        = "boss"
+	SpriteMinion      = "minion"
+	SpriteTownNPC     = "town_npc"
+	SpriteCorpse      = "corpse"
+	SpritePlayer      = "player"
+	SpriteParty       = "party"
+	SpriteOtherPlayer = "other_player"
+	SpritePortal      = "portal"
+	SpriteShrine      = "shrine"
+	SpriteChest       = "chest"
+)
+
+// MonsterFlag bits
+const (
+	monsterSuperUnique = 0x02
+	monsterChampion    = 0x04
+	monsterUnique      = 0x08
+)
+
+// noParty is the party id of players that are not in a party
+const noParty = 0xFFFF
+
+// MobSprite returns the sprite kind of a monster.
+func MobSprite(mob globals.Mob) string {
+	switch {
+	case mob.IsCorpse:
+		return SpriteCorpse
+	case mob.IsPlayerMinion:
+		return SpriteMinion
+	case mob.IsTownNPC != "":
+		return SpriteTownNPC
+	case mob.IsBoss:
+		return SpriteBoss
+	case mob.MonsterFlag&monsterSuperUnique != 0:
+		return SpriteSuperUnique
+	case mob.MonsterFlag&monsterUnique != 0:
+		return SpriteUnique
+	case mob.MonsterFlag&monsterChampion != 0:
+		return SpriteChampion
+	default:
+		return SpriteMob
+	}
+}
+
+// PlayerSprite returns the sprite kind of another player, telling party
+// members apart using the roster entry of the local player with unit id self.
+func PlayerSprite(player globals.Player, self uint32, roster []globals.Player) string {
+	if player.IsCorpse {
+		return SpriteCorpse
+	}
+	partyId := uint16(noParty)
+	for _, entry := range roster {
+		if entry.UnitId == self {
+			partyId = entry.PartyId
+		}
+	}
+	if partyId != noParty {
+		for _, entry := range roster {
+			if entry.UnitId == player.UnitId && entry.PartyId == partyId {
+				return SpriteParty
+			}
+		}
+	}
+	return SpriteOtherPlayer
+}
+
+// ObjectSprite returns the sprite kind of an object, or "" for objects that aren't drawn.
+func ObjectSprite(object globals.Object) string {
+	switch {
+	case object.IsPortal || object.IsRedPortal:
+		return SpritePortal
+	case object.IsShrine:
+		return SpriteShrine
+	case object.IsChest:
+		return SpriteChest
+	default:
+		return ""
+	}
+}
+
+// ItemSprite returns the sprite kind of an item of the given quality.
+func ItemSprite(quality types.QualityNo) string {
+	return "item_" + strings.ToLower(quality.ToString())
+}
+
+// MissileSprite returns the sprite kind of a missile.
+func MissileSprite(missile globals.Missile) string {
+	return "missile_" + strings.ToLower(missile.Category)
+}
+
+// ItemQualities and MissileCategories list the values the item and missile kinds are made from
+var (
+	ItemQualities = []types.QualityNo{
+		types.QualityLowQuality, types.QualityNormal, types.QualitySuperior, types.QualityMagic,
+		types.QualitySet, types.QualityRare, types.QualityUnique, types.QualityCrafted,
+	}
+	MissileCategories = []string{
+		"PhysicalMajor", "PhysicalMinor", "FireMajor", "FireMinor", "IceMajor", "IceMinor",
+		"LightMajor", "LightMinor", "PoisonMajor", "PoisonMinor", "MagicMajor", "MagicMinor", "Other",
+	}
+)
+
+// SpriteKinds returns every sprite kind the overlay draws.
+func SpriteKinds() []string {
+	kinds := []string{
+		SpriteMob, SpriteChampion, SpriteUnique, SpriteSuperUnique, SpriteBoss,
+		SpriteMinion, SpriteTownNPC, SpriteCorpse,
+		SpritePlayer, SpriteParty, SpriteOtherPlayer,
+		SpritePortal, SpriteShrine, SpriteChest,
+	}
+	for _, quality := range ItemQualities {
+		kinds = append(kinds, ItemSprite(quality))
+	}
+	for _, category := range MissileCategories {
+		kinds = append(kinds, MissileSprite(globals.Missile{Category: category}))
+	}
+	return kinds
+}
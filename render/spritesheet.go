@@ -0,0 +1,64 @@
+// render/spritesheet.go
+
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+
+	"GalyMap/config"
+)
+
+// SpriteStyle is how a sprite kind is drawn: an atlas cell tinted with a color at a size in pixels
+type SpriteStyle struct {
+	Cell  string
+	Color color.NRGBA
+	Size  image.Point
+}
+
+// AddSpriteSheet adds the sprites listed in manifest to b, cropped from its
+// sheet, and returns their styles: untinted, at the size of the manifest.
+func AddSpriteSheet(b *AtlasBuilder, manifest *config.SpriteManifest) (map[string]SpriteStyle, error) {
+	file, err := os.Open(manifest.Sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sprite sheet: %w", err)
+	}
+	defer file.Close()
+
+	sheet, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode sprite sheet: %w", err)
+	}
+
+	styles := make(map[string]SpriteStyle)
+	for kind, rect := range manifest.Sprites {
+		bounds := image.Rect(rect.X, rect.Y, rect.X+rect.W, rect.Y+rect.H)
+		if !bounds.In(sheet.Bounds()) {
+			return nil, fmt.Errorf("sprite %s at %v is outside the %v sprite sheet", kind, bounds, sheet.Bounds().Size())
+		}
+		sprite := image.NewNRGBA(image.Rect(0, 0, rect.W, rect.H))
+		draw.Draw(sprite, sprite.Bounds(), sheet, bounds.Min, draw.Src)
+		b.Add("sprite_"+kind, sprite)
+
+		size := rect.Size
+		if size <= 0 {
+			size = manifest.Size
+		}
+		styles[kind] = SpriteStyle{
+			Cell:  "sprite_" + kind,
+			Color: color.NRGBA{R: 255, G: 255, B: 255, A: 255},
+			Size:  fitSize(rect.W, rect.H, size),
+		}
+	}
+	return styles, nil
+}
+
+// fitSize scales a w by h sprite to fit a square of size pixels, keeping its aspect ratio.
+func fitSize(w, h, size int) image.Point {
+	scale := float64(size) / float64(max(w, h))
+	return image.Point{X: max(int(float64(w)*scale), 1), Y: max(int(float64(h)*scale), 1)}
+}
@@ -0,0 +1,183 @@
+// render/text.go
+
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// glyphPadding leaves room around each glyph for its outline
+const glyphPadding = 1
+
+// TextStyle is how text is drawn. Outline and shadow are left out when their alpha is zero.
+type TextStyle struct {
+	Color   color.NRGBA
+	Outline color.NRGBA
+	Shadow  color.NRGBA
+}
+
+// glyph is a rasterized rune in the atlas
+type glyph struct {
+	cell    string      // white glyph
+	outline string      // white glyph grown by a pixel, empty for blank glyphs
+	offset  image.Point // top left of the cell from the dot on the baseline
+	size    image.Point
+	advance float32
+}
+
+// Font draws text from glyph cells of an atlas, rasterized once from a TrueType font
+type Font struct {
+	face    font.Face
+	glyphs  map[rune]glyph
+	ascent  float32
+	height  float32
+	missing rune
+}
+
+// fontRunes are the runes rasterized into the atlas: printable ASCII and Latin-1
+var fontRunes = [][2]rune{{0x20, 0x7E}, {0xA0, 0xFF}}
+
+// NewFont rasterizes the TrueType or OpenType font ttf at size pixels and
+// adds its glyphs to b as cells prefixed with name.
+func NewFont(b *AtlasBuilder, name string, ttf []byte, size float64) (*Font, error) {
+	parsed, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font %s: %w", name, err)
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load font %s: %w", name, err)
+	}
+
+	metrics := face.Metrics()
+	f := &Font{
+		face:    face,
+		glyphs:  make(map[rune]glyph),
+		ascent:  float32(metrics.Ascent.Ceil()),
+		height:  float32(metrics.Height.Ceil()),
+		missing: '?',
+	}
+	for _, runes := range fontRunes {
+		for r := runes[0]; r <= runes[1]; r++ {
+			bounds, mask, maskPoint, advance, ok := face.Glyph(fixed.Point26_6{}, r)
+			if !ok {
+				continue
+			}
+			g := glyph{advance: float32(advance.Round())}
+			if !bounds.Empty() {
+				fill := glyphImage(mask, maskPoint, bounds.Size())
+				g.cell = fmt.Sprintf("%s_%04x", name, r)
+				g.outline = g.cell + "_outline"
+				g.offset = bounds.Min.Sub(image.Point{X: glyphPadding, Y: glyphPadding})
+				g.size = fill.Bounds().Size()
+				b.Add(g.cell, fill)
+				b.Add(g.outline, dilate(fill))
+			}
+			f.glyphs[r] = g
+		}
+	}
+	return f, nil
+}
+
+// glyphImage copies a glyph mask of size into a white image, padded for the outline.
+func glyphImage(mask image.Image, maskPoint, size image.Point) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, size.X+2*glyphPadding, size.Y+2*glyphPadding))
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			_, _, _, a := mask.At(maskPoint.X+x, maskPoint.Y+y).RGBA()
+			img.SetNRGBA(x+glyphPadding, y+glyphPadding, color.NRGBA{R: 255, G: 255, B: 255, A: uint8(a >> 8)})
+		}
+	}
+	return img
+}
+
+// dilate grows the opaque parts of img by a pixel in every direction.
+func dilate(img *image.NRGBA) *image.NRGBA {
+	bounds := img.Bounds()
+	grown := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var alpha uint8
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if p := image.Pt(x+dx, y+dy); p.In(bounds) {
+						alpha = max(alpha, img.NRGBAAt(p.X, p.Y).A)
+					}
+				}
+			}
+			grown.SetNRGBA(x, y, color.NRGBA{R: 255, G: 255, B: 255, A: alpha})
+		}
+	}
+	return grown
+}
+
+// LineHeight returns the height of a line of text in pixels.
+func (f *Font) LineHeight() float32 {
+	return f.height
+}
+
+// Measure returns the width and height of text in pixels.
+func (f *Font) Measure(text string) (float32, float32) {
+	var width float32
+	previous := rune(-1)
+	for _, r := range text {
+		r = f.resolve(r)
+		if previous >= 0 {
+			width += float32(f.face.Kern(previous, r).Round())
+		}
+		width += f.glyphs[r].advance
+		previous = r
+	}
+	return width, f.height
+}
+
+// AddText queues text with its top left corner at x, y, drawing the shadows,
+// then the outlines, then the glyphs so that neighbouring glyphs don't cover
+// each other's fill.
+func (f *Font) AddText(frame *Frame, text string, x, y float32, style TextStyle) {
+	x, y = float32(math.Round(float64(x))), float32(math.Round(float64(y)))
+	if style.Shadow.A > 0 {
+		f.addGlyphs(frame, text, x+1, y+1, false, style.Shadow)
+	}
+	if style.Outline.A > 0 {
+		f.addGlyphs(frame, text, x, y, true, style.Outline)
+	}
+	f.addGlyphs(frame, text, x, y, false, style.Color)
+}
+
+func (f *Font) addGlyphs(frame *Frame, text string, x, y float32, outline bool, c color.NRGBA) {
+	baseline := y + f.ascent
+	previous := rune(-1)
+	for _, r := range text {
+		r = f.resolve(r)
+		if previous >= 0 {
+			x += float32(f.face.Kern(previous, r).Round())
+		}
+		g := f.glyphs[r]
+		if g.cell != "" {
+			cell := g.cell
+			if outline {
+				cell = g.outline
+			}
+			w, h := float32(g.size.X), float32(g.size.Y)
+			frame.Add(cell, x+float32(g.offset.X)+w/2, baseline+float32(g.offset.Y)+h/2, w, h, c)
+		}
+		x += g.advance
+		previous = r
+	}
+}
+
+// resolve returns r, or the replacement rune if the font has no glyph for it.
+func (f *Font) resolve(r rune) rune {
+	if _, ok := f.glyphs[r]; ok {
+		return r
+	}
+	return f.missing
+}
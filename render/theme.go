@@ -0,0 +1,73 @@
+// render/theme.go
+
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"GalyMap/config"
+)
+
+// themeSprite is the theme shape that draws a kind with its sprite sheet sprite
+const themeSprite = "sprite"
+
+// missingStyleColor marks kinds that neither the theme nor the sprite sheet cover
+var missingStyleColor = color.NRGBA{R: 255, G: 0, B: 255, A: 255}
+
+// ThemeStyles returns the style of every kind in SpriteKinds as set by
+// theme. sprites are the styles of the sprite sheet, from AddSpriteSheet,
+// used by kinds with the sprite shape and by kinds the theme leaves out.
+// Shapes are atlas cells, see AddShapes.
+func ThemeStyles(theme *config.Theme, sprites map[string]SpriteStyle) (map[string]SpriteStyle, error) {
+	styles := make(map[string]SpriteStyle)
+	for _, kind := range SpriteKinds() {
+		themed, ok := theme.Styles[kind]
+		sprite, hasSprite := sprites[kind]
+		if !ok {
+			if hasSprite {
+				styles[kind] = sprite
+			} else {
+				styles[kind] = SpriteStyle{Cell: ShapeSquare, Color: missingStyleColor, Size: image.Point{X: theme.Size, Y: theme.Size}}
+			}
+			continue
+		}
+
+		style := SpriteStyle{Color: color.NRGBA{R: 255, G: 255, B: 255, A: 255}}
+		if themed.Color != "" {
+			c, err := config.ParseColor(themed.Color)
+			if err != nil {
+				return nil, fmt.Errorf("style %s: %w", kind, err)
+			}
+			style.Color = c
+		}
+
+		switch shape := themed.Shape; {
+		case shape == themeSprite || (shape == "" && hasSprite):
+			if !hasSprite {
+				return nil, fmt.Errorf("style %s: the sprite sheet has no sprite for it", kind)
+			}
+			style.Cell = sprite.Cell
+			style.Size = sprite.Size
+			if themed.Size > 0 {
+				style.Size = fitSize(sprite.Size.X, sprite.Size.Y, themed.Size)
+			}
+		default:
+			if shape == "" {
+				shape = ShapeSquare
+			}
+			if _, ok := shapeDistance[shape]; !ok {
+				return nil, fmt.Errorf("style %s: unknown shape %q", kind, shape)
+			}
+			size := themed.Size
+			if size <= 0 {
+				size = theme.Size
+			}
+			style.Cell = shape
+			style.Size = image.Point{X: size, Y: size}
+		}
+		styles[kind] = style
+	}
+	return styles, nil
+}
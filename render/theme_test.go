@@ -0,0 +1,139 @@
+// render/theme_test.go
+
+package render
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GalyMap/config"
+)
+
+func TestThemeStyles(t *testing.T) {
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	orange := color.NRGBA{R: 255, G: 128, A: 255}
+	sprites := map[string]SpriteStyle{
+		SpriteMob:    {Cell: "sprite:mob", Color: white, Size: image.Point{X: 10, Y: 16}},
+		SpritePlayer: {Cell: "sprite:player", Color: white, Size: image.Point{X: 12, Y: 16}},
+	}
+
+	tests := []struct {
+		name    string
+		styles  map[string]config.ThemeStyle
+		kind    string
+		want    SpriteStyle
+		wantErr string // part of the error, "" for none
+	}{
+		{
+			name:   "shape",
+			styles: map[string]config.ThemeStyle{SpriteBoss: {Color: "#ff8000", Shape: ShapeCross, Size: 20}},
+			kind:   SpriteBoss,
+			want:   SpriteStyle{Cell: ShapeCross, Color: orange, Size: image.Point{X: 20, Y: 20}},
+		},
+		{
+			name:   "theme size and white by default",
+			styles: map[string]config.ThemeStyle{SpriteShrine: {Shape: ShapeDiamond}},
+			kind:   SpriteShrine,
+			want:   SpriteStyle{Cell: ShapeDiamond, Color: white, Size: image.Point{X: 16, Y: 16}},
+		},
+		{
+			name:   "square by default",
+			styles: map[string]config.ThemeStyle{SpritePortal: {Color: "#ff8000"}},
+			kind:   SpritePortal,
+			want:   SpriteStyle{Cell: ShapeSquare, Color: orange, Size: image.Point{X: 16, Y: 16}},
+		},
+		{
+			name:   "tinted sprite",
+			styles: map[string]config.ThemeStyle{SpriteMob: {Color: "#ff8000", Shape: "sprite"}},
+			kind:   SpriteMob,
+			want:   SpriteStyle{Cell: "sprite:mob", Color: orange, Size: image.Point{X: 10, Y: 16}},
+		},
+		{
+			name:   "sprite without a shape, resized",
+			styles: map[string]config.ThemeStyle{SpritePlayer: {Size: 32}},
+			kind:   SpritePlayer,
+			want:   SpriteStyle{Cell: "sprite:player", Color: white, Size: image.Point{X: 24, Y: 32}},
+		},
+		{
+			name:   "left out, with a sprite",
+			styles: map[string]config.ThemeStyle{"dragon": {Shape: ShapeDot}},
+			kind:   SpriteMob,
+			want:   sprites[SpriteMob],
+		},
+		{
+			name:   "left out, without a sprite",
+			styles: map[string]config.ThemeStyle{"dragon": {Shape: ShapeDot}},
+			kind:   SpriteChest,
+			want:   SpriteStyle{Cell: ShapeSquare, Color: missingStyleColor, Size: image.Point{X: 16, Y: 16}},
+		},
+		{
+			name:    "malformed color",
+			styles:  map[string]config.ThemeStyle{SpriteBoss: {Color: "#ff80"}},
+			wantErr: "style boss: invalid color",
+		},
+		{
+			name:    "unknown shape",
+			styles:  map[string]config.ThemeStyle{SpriteBoss: {Shape: "star"}},
+			wantErr: `style boss: unknown shape "star"`,
+		},
+		{
+			name:    "sprite missing from the sheet",
+			styles:  map[string]config.ThemeStyle{SpriteChest: {Shape: "sprite"}},
+			wantErr: "style chest: the sprite sheet has no sprite",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			styles, err := ThemeStyles(&config.Theme{Size: 16, Styles: tt.styles}, sprites)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ThemeStyles error %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ThemeStyles: %v", err)
+			}
+			if len(styles) != len(SpriteKinds()) {
+				t.Errorf("ThemeStyles styled %d kinds, want %d", len(styles), len(SpriteKinds()))
+			}
+			if _, ok := styles["dragon"]; ok {
+				t.Errorf("ThemeStyles styled the unknown kind dragon")
+			}
+			if got := styles[tt.kind]; got != tt.want {
+				t.Errorf("style %s = %+v, want %+v", tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuiltinThemeStyles checks that the built-in theme and sprite manifest
+// draw every kind, with a shape where the sprite sheet has no sprite.
+func TestBuiltinThemeStyles(t *testing.T) {
+	dir := t.TempDir()
+	theme, err := config.LoadTheme(filepath.Join(dir, "theme.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := config.LoadSpriteManifest(filepath.Join(dir, "sprites.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sprites := make(map[string]SpriteStyle)
+	for kind := range manifest.Sprites {
+		sprites[kind] = SpriteStyle{Cell: "sprite:" + kind, Size: image.Point{X: manifest.Size, Y: manifest.Size}}
+	}
+
+	styles, err := ThemeStyles(theme, sprites)
+	if err != nil {
+		t.Fatalf("ThemeStyles: %v", err)
+	}
+	for _, kind := range SpriteKinds() {
+		if style := styles[kind]; style.Color == missingStyleColor {
+			t.Errorf("kind %s has neither a theme style nor a sprite", kind)
+		}
+	}
+}
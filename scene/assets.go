@@ -0,0 +1,57 @@
+// scene/assets.go
+
+package scene
+
+import (
+	"fmt"
+
+	"GalyMap/config"
+	"GalyMap/render"
+
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+// labelFontSize is the pixel size of the labels and the info panel text
+const labelFontSize = 13
+
+// Assets are what frames are drawn with: the atlas, the style of every entity kind and the label font
+type Assets struct {
+	Atlas  *render.Atlas
+	Styles map[string]render.SpriteStyle
+	Font   *render.Font
+}
+
+// LoadAssets packs the sprite sheet, the shapes and the label font into the
+// atlas and styles the entity kinds with the theme. The font is rasterized at
+// uiScale times its size, and never smaller since smaller text is unreadable,
+// so that text stays sharp on views of that UIScale.
+func LoadAssets(manifestFile, themeFile string, uiScale float64) (*Assets, error) {
+	manifest, err := config.LoadSpriteManifest(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := config.LoadTheme(themeFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder render.AtlasBuilder
+	sheetStyles, err := render.AddSpriteSheet(&builder, manifest)
+	if err != nil {
+		return nil, err
+	}
+	render.AddShapes(&builder)
+	font, err := render.NewFont(&builder, "label", gobold.TTF, labelFontSize*max(uiScale, 1))
+	if err != nil {
+		return nil, err
+	}
+	styles, err := render.ThemeStyles(theme, sheetStyles)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", themeFile, err)
+	}
+	atlas, err := builder.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &Assets{Atlas: atlas, Styles: styles, Font: font}, nil
+}
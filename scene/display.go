@@ -0,0 +1,155 @@
+// scene/display.go
+
+package scene
+
+import (
+	"image/color"
+	"log"
+	"math"
+
+	"GalyMap/config"
+)
+
+// DisplayMode is how the overlay shows the map
+type DisplayMode string
+
+const (
+	DisplayOverlay DisplayMode = "overlay" // full screen, centered on the player like the in-game automap
+	DisplayMinimap DisplayMode = "minimap" // in a corner of the screen
+	DisplayHidden  DisplayMode = "hidden"  // nothing at all
+)
+
+// displayModes is the order the modes are cycled through
+var displayModes = []DisplayMode{DisplayOverlay, DisplayMinimap, DisplayHidden}
+
+// ZoomLevels are the zooms stepped through, 1 being the scale of the in-game automap
+var ZoomLevels = []float64{0.25, 0.35, 0.5, 0.75, 1, 1.25, 1.5, 2, 3}
+
+var minimapBackground = color.NRGBA{A: 140}
+
+// minimapCorners are the corners the minimap can be placed in, as the side of the screen in x and y
+var minimapCorners = map[string][2]float64{
+	"top-left":     {0, 0},
+	"top-right":    {1, 0},
+	"bottom-left":  {0, 1},
+	"bottom-right": {1, 1},
+}
+
+// Display is the display mode and the zoom of each mode
+type Display struct {
+	Mode    DisplayMode
+	Zoom    float64 // of the full-screen overlay
+	Minimap config.MinimapSettings
+}
+
+// NewDisplay returns the display set in settings, falling back to the
+// full-screen overlay for unknown modes and to the top right corner for
+// unknown minimap corners.
+func NewDisplay(cfg *config.Settings) Display {
+	d := Display{Mode: DisplayMode(cfg.DisplayMode), Zoom: cfg.Zoom, Minimap: cfg.Minimap}
+	if !d.Mode.valid() {
+		log.Printf("Unknown display mode %q, showing the overlay", cfg.DisplayMode)
+		d.Mode = DisplayOverlay
+	}
+	if _, ok := minimapCorners[d.Minimap.Corner]; !ok {
+		log.Printf("Unknown minimap corner %q, using top-right", d.Minimap.Corner)
+		d.Minimap.Corner = "top-right"
+	}
+	return d
+}
+
+func (m DisplayMode) valid() bool {
+	for _, mode := range displayModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Save stores the mode and the zooms in settings.
+func (d Display) Save(cfg *config.Settings) {
+	cfg.DisplayMode = string(d.Mode)
+	cfg.Zoom = d.Zoom
+	cfg.Minimap = d.Minimap
+}
+
+// NextMode switches to the mode after the current one, wrapping around.
+func (d *Display) NextMode() {
+	for i, mode := range displayModes {
+		if mode == d.Mode {
+			d.Mode = displayModes[(i+1)%len(displayModes)]
+			return
+		}
+	}
+	d.Mode = DisplayOverlay
+}
+
+// StepZoom moves the zoom of the current mode steps levels of ZoomLevels in,
+// or out when negative, from the level closest to it. Nothing changes while hidden.
+func (d *Display) StepZoom(steps int) {
+	switch d.Mode {
+	case DisplayOverlay:
+		d.Zoom = stepZoom(d.Zoom, steps)
+	case DisplayMinimap:
+		d.Minimap.Zoom = stepZoom(d.Minimap.Zoom, steps)
+	}
+}
+
+// CurrentZoom returns the zoom of the current mode, 1 while hidden.
+func (d Display) CurrentZoom() float64 {
+	switch d.Mode {
+	case DisplayOverlay:
+		return zoomOrDefault(d.Zoom)
+	case DisplayMinimap:
+		return zoomOrDefault(d.Minimap.Zoom)
+	}
+	return 1
+}
+
+func stepZoom(zoom float64, steps int) float64 {
+	zoom = zoomOrDefault(zoom)
+	closest := 0
+	for i, level := range ZoomLevels {
+		if math.Abs(level-zoom) < math.Abs(ZoomLevels[closest]-zoom) {
+			closest = i
+		}
+	}
+	return ZoomLevels[max(0, min(len(ZoomLevels)-1, closest+steps))]
+}
+
+// zoomOrDefault returns zoom, or 1 when it isn't set.
+func zoomOrDefault(zoom float64) float64 {
+	if zoom <= 0 {
+		return 1
+	}
+	return zoom
+}
+
+// View returns the view of the display over a game client area of width by
+// height physical pixels. The minimap is sized and placed in pixels at 1080p
+// like the rest of the UI, and centered on the player rather than nudged onto
+// the game's own center.
+func (d Display) View(width, height int) View {
+	v := ViewForWindow(width, height)
+	if d.Mode != DisplayMinimap {
+		v.Scale *= zoomOrDefault(d.Zoom)
+		return v
+	}
+
+	m := d.Minimap
+	w := math.Min(v.UISize(float64(m.Width)), v.Width)
+	h := math.Min(v.UISize(float64(m.Height)), v.Height)
+	margin := v.UISize(float64(m.Margin))
+	side := minimapCorners[m.Corner]
+	v.Viewport = Viewport{
+		X:      math.Max(0, margin+side[0]*(v.Width-w-2*margin)),
+		Y:      math.Max(0, margin+side[1]*(v.Height-h-2*margin)),
+		Width:  w,
+		Height: h,
+	}
+	v.Background = minimapBackground
+	v.Scale *= zoomOrDefault(m.Zoom)
+	v.OffsetX, v.OffsetY = 0, 0
+	return v
+}
@@ -0,0 +1,117 @@
+// scene/display_test.go
+
+package scene
+
+import (
+	"path/filepath"
+	"testing"
+
+	"GalyMap/config"
+)
+
+func TestNextMode(t *testing.T) {
+	tests := []struct {
+		mode DisplayMode
+		want DisplayMode
+	}{
+		{DisplayOverlay, DisplayMinimap},
+		{DisplayMinimap, DisplayHidden},
+		{DisplayHidden, DisplayOverlay},
+		{"sideways", DisplayOverlay},
+		{"", DisplayOverlay},
+	}
+	for _, tt := range tests {
+		d := Display{Mode: tt.mode}
+		d.NextMode()
+		if d.Mode != tt.want {
+			t.Errorf("NextMode from %q = %q, want %q", tt.mode, d.Mode, tt.want)
+		}
+	}
+
+	// A full cycle comes back to the start
+	d := Display{Mode: DisplayMinimap}
+	for range displayModes {
+		d.NextMode()
+	}
+	if d.Mode != DisplayMinimap {
+		t.Errorf("a full cycle from minimap ends at %q", d.Mode)
+	}
+}
+
+func TestStepZoom(t *testing.T) {
+	tests := []struct {
+		name  string
+		zoom  float64
+		steps int
+		want  float64
+	}{
+		{"in", 1, 1, 1.25},
+		{"out", 1, -1, 0.75},
+		{"several", 0.5, 3, 1.25},
+		{"from between levels", 1.1, 1, 1.25},
+		{"unset is 1", 0, -2, 0.5},
+		{"clamped at the max", 2, 5, 3},
+		{"at the max", 3, 1, 3},
+		{"past the max", 8, 1, 3},
+		{"clamped at the min", 0.35, -4, 0.25},
+		{"at the min", 0.25, -1, 0.25},
+		{"past the min", 0.01, -1, 0.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlay := Display{Mode: DisplayOverlay, Zoom: tt.zoom, Minimap: config.MinimapSettings{Zoom: 0.5}}
+			overlay.StepZoom(tt.steps)
+			if overlay.Zoom != tt.want || overlay.CurrentZoom() != tt.want || overlay.Minimap.Zoom != 0.5 {
+				t.Errorf("overlay zoom %g stepped %d = %g, minimap %g; want %g, minimap 0.5", tt.zoom, tt.steps, overlay.Zoom, overlay.Minimap.Zoom, tt.want)
+			}
+
+			minimap := Display{Mode: DisplayMinimap, Zoom: 1.5, Minimap: config.MinimapSettings{Zoom: tt.zoom}}
+			minimap.StepZoom(tt.steps)
+			if minimap.Minimap.Zoom != tt.want || minimap.CurrentZoom() != tt.want || minimap.Zoom != 1.5 {
+				t.Errorf("minimap zoom %g stepped %d = %g, overlay %g; want %g, overlay 1.5", tt.zoom, tt.steps, minimap.Minimap.Zoom, minimap.Zoom, tt.want)
+			}
+		})
+	}
+
+	hidden := Display{Mode: DisplayHidden, Zoom: 1, Minimap: config.MinimapSettings{Zoom: 0.5}}
+	hidden.StepZoom(1)
+	if hidden.Zoom != 1 || hidden.Minimap.Zoom != 0.5 || hidden.CurrentZoom() != 1 {
+		t.Errorf("stepping while hidden changed the zooms to %g and %g", hidden.Zoom, hidden.Minimap.Zoom)
+	}
+}
+
+// TestDisplaySave checks that each mode keeps its own zoom through the settings file.
+func TestDisplaySave(t *testing.T) {
+	cfg := &config.Settings{DisplayMode: "overlay", Zoom: 1, Minimap: config.MinimapSettings{Corner: "bottom-left", Width: 360, Height: 270, Zoom: 0.5}}
+	d := NewDisplay(cfg)
+	d.StepZoom(2)
+	d.NextMode()
+	d.StepZoom(-1)
+	d.Save(cfg)
+
+	if cfg.DisplayMode != "minimap" || cfg.Zoom != 1.5 || cfg.Minimap.Zoom != 0.35 {
+		t.Errorf("saved mode %q, zoom %g, minimap zoom %g; want minimap, 1.5, 0.35", cfg.DisplayMode, cfg.Zoom, cfg.Minimap.Zoom)
+	}
+	if cfg.Minimap.Corner != "bottom-left" || cfg.Minimap.Width != 360 || cfg.Minimap.Height != 270 {
+		t.Errorf("saving changed the minimap placement to %+v", cfg.Minimap)
+	}
+
+	// The next start picks up where this one left off
+	filePath := filepath.Join(t.TempDir(), "settings.yaml")
+	if err := config.SaveConfig(filePath, cfg); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := config.LoadConfig(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewDisplay(loaded)
+	if restored != d {
+		t.Errorf("restored display %+v, want %+v", restored, d)
+	}
+	restored.NextMode()
+	restored.NextMode()
+	if restored.CurrentZoom() != 1.5 {
+		t.Errorf("back in the overlay the zoom is %g, want 1.5", restored.CurrentZoom())
+	}
+}
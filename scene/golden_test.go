@@ -0,0 +1,189 @@
+// scene/golden_test.go
+
+package scene
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"GalyMap/globals"
+	"GalyMap/mapdata"
+	"GalyMap/render"
+
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata/golden")
+
+const (
+	// goldenTolerance is how far a channel may be off the golden image, since
+	// floating point results differ slightly across architectures
+	goldenTolerance = 4
+	// goldenMaxOff is the share of pixels, in thousandths, that may be further off
+	goldenMaxOff = 1
+)
+
+// goldenAssets are the shapes and the label font without the sprite sheet,
+// which none of the golden layers draw.
+func goldenAssets(t *testing.T) *Assets {
+	t.Helper()
+	var builder render.AtlasBuilder
+	render.AddShapes(&builder)
+	font, err := render.NewFont(&builder, "label", gobold.TTF, labelFontSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	atlas, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Assets{Atlas: atlas, Font: font}
+}
+
+// goldenState is a player standing in the middle of goldenLevel.
+func goldenState() *globals.GameState {
+	return &globals.GameState{
+		Version:     1,
+		PlayerName:  "Gälyna",
+		PlayerLevel: 87,
+		Pos:         globals.UnitPosition{X: 5012, Y: 5008},
+		LevelNo:     2,
+		Difficulty:  2,
+		MapSeed:     0x1A2B3C4D,
+	}
+}
+
+// goldenLevel is a room with a pillar, a corridor leaving it to the east and
+// an exit, a waypoint and a quest object, plus an exit too far away to be drawn.
+func goldenLevel() *mapdata.Level {
+	return &mapdata.Level{
+		ID:     2,
+		Offset: mapdata.Point{X: 5000, Y: 5000},
+		Size:   mapdata.Size{Width: 32, Height: 16},
+		Objects: []mapdata.Object{
+			{ID: 3, Type: mapdata.ObjectExit, X: 30, Y: 7, IsGoodExit: true},
+			{ID: 8, Type: mapdata.ObjectExit, X: 200, Y: -150},
+			{ID: 119, Type: mapdata.ObjectObject, Name: "Waypoint", X: 6, Y: 4},
+		},
+		Map: [][]int{
+			{32},
+			{2, 20, 10},
+			{2, 20, 10},
+			{2, 20, 10},
+			{2, 20, 10},
+			{2, 8, 4, 8, 10},
+			{2, 8, 4, 8, 10},
+			{2, 20, 1, 9},
+			{2, 20, 1, 9},
+			{2, 8, 4, 8, 10},
+			{2, 20, 10},
+			{2, 20, 10},
+			{2, 20, 10},
+			{2, 20, 10},
+			{2, 20, 10},
+			{32},
+		},
+	}
+}
+
+func TestGoldenLayers(t *testing.T) {
+	assets := goldenAssets(t)
+	view := View{Width: 320, Height: 200, Scale: 4.6, UIScale: 1}
+	level := NewLevel(goldenLevel())
+
+	tests := []struct {
+		name    string
+		compose func(c *composer)
+	}{
+		{"level", func(c *composer) {
+			c.addLevel(level)
+		}},
+		{"markers", func(c *composer) {
+			markers := append(slices.Clip(level.Markers), mapdata.Marker{Kind: mapdata.MarkerQuest, Label: "Cairn Stones", X: 5004, Y: 5012})
+			c.addMarkers(markers)
+			c.labels = nil
+		}},
+		{"labels", func(c *composer) {
+			c.queueLabel("Den of Evil", 160, 100, true, labelColor)
+			c.queueLabel("Stony Field", 160, 100, false, panelTitleColor)
+			c.queueLabel("Clamped to the left edge", 0, 40, false, labelColor)
+			c.queueLabel("Clamped to the bottom right", 320, 200, true, labelColor)
+			c.addLabels()
+		}},
+		{"infopanel", func(c *composer) {
+			c.addInfoPanel()
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame := render.NewFrame(assets.Atlas)
+			tt.compose(&composer{frame: frame, assets: assets, view: view, state: goldenState()})
+			renderer := render.NewSoftwareRenderer(int(view.Width), int(view.Height))
+			renderer.Render(frame)
+			checkGolden(t, filepath.Join("testdata", "golden", tt.name+".png"), renderer.Image)
+		})
+	}
+}
+
+// checkGolden compares img with the golden image at path, or rewrites it with -update.
+func checkGolden(t *testing.T, path string, img *image.RGBA) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		if err := png.Encode(file, img); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v, run the test with -update to create it", err)
+	}
+	defer file.Close()
+	decoded, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("image is %v, golden image is %v", img.Bounds(), decoded.Bounds())
+	}
+	golden := image.NewRGBA(decoded.Bounds())
+	for y := golden.Rect.Min.Y; y < golden.Rect.Max.Y; y++ {
+		for x := golden.Rect.Min.X; x < golden.Rect.Max.X; x++ {
+			golden.Set(x, y, decoded.At(x, y))
+		}
+	}
+
+	off := 0
+	for i := range img.Pix {
+		if i%4 == 0 && pixelOff(img.Pix[i:i+4], golden.Pix[i:i+4]) {
+			off++
+		}
+	}
+	if size := img.Bounds().Size(); off*1000 > size.X*size.Y*goldenMaxOff {
+		t.Errorf("%d pixels differ from %s, run the test with -update if the change is intended", off, path)
+	}
+}
+
+// pixelOff reports whether a channel of got is further than goldenTolerance from want.
+func pixelOff(got, want []uint8) bool {
+	for i := range got {
+		if d := int(got[i]) - int(want[i]); d > goldenTolerance || d < -goldenTolerance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// scene/infopanel.go
+
+package scene
+
+import (
+	"fmt"
+	"image/color"
+
+	"GalyMap/mapdata"
+	"GalyMap/render"
+)
+
+const (
+	panelMargin  = 12 // distance of the info panel from the top left corner of the overlay
+	panelPadding = 6
+)
+
+var (
+	panelBackground = color.NRGBA{A: 150}
+	panelTitleColor = color.NRGBA{R: 255, G: 215, B: 0, A: 255}
+	difficultyNames = []string{"Normal", "Nightmare", "Hell"}
+)
+
+// addInfoPanel queues a panel with the player, level, difficulty and map seed.
+func (c *composer) addInfoPanel() {
+	state := c.state
+	font := c.assets.Font
+
+	difficulty := fmt.Sprintf("Difficulty %d", state.Difficulty)
+	if int(state.Difficulty) < len(difficultyNames) {
+		difficulty = difficultyNames[state.Difficulty]
+	}
+	lines := []string{
+		fmt.Sprintf("%s (level %d)", state.PlayerName, state.PlayerLevel),
+		mapdata.LevelName(int(state.LevelNo)),
+		difficulty,
+		fmt.Sprintf("Seed %08X", state.MapSeed),
+	}
+
+	var panelWidth float32
+	for _, line := range lines {
+		lineWidth, _ := font.Measure(line)
+		panelWidth = max(panelWidth, lineWidth)
+	}
+	lineHeight := font.LineHeight()
+	margin, padding := float32(c.view.UISize(panelMargin)), float32(c.view.UISize(panelPadding))
+	panelWidth += 2 * padding
+	panelHeight := float32(len(lines))*lineHeight + 2*padding
+	c.frame.Add(render.CellSolid, margin+panelWidth/2, margin+panelHeight/2, panelWidth, panelHeight, panelBackground)
+
+	for i, line := range lines {
+		style := render.TextStyle{Color: labelColor, Shadow: outlineColor}
+		if i == 0 {
+			style.Color = panelTitleColor
+		}
+		font.AddText(c.frame, line, margin+padding, margin+padding+float32(i)*lineHeight, style)
+	}
+}
@@ -0,0 +1,64 @@
+// scene/items.go
+
+package scene
+
+import (
+	"image"
+
+	"GalyMap/globals"
+	"GalyMap/types"
+)
+
+// DisplayedItems runs the items on the ground that aren't in filtered yet
+// through the NIP rules. It returns the items to draw, the displayed items
+// still on the ground and the matching new ones, along with filtered and the
+// new items, which are never run through the rules again. It keeps no state:
+// the tick loop stores both lists for the next tick and the renderer.
+func DisplayedItems(state *globals.GameState, displayed, filtered []types.ItemFootprint) ([]types.ItemFootprint, []types.ItemFootprint) {
+	items := state.Items
+	levelNo := state.LevelNo
+	unfiltered := make([]types.Item, 0)
+
+	for _, item := range items {
+		if item.ItemLoc == 3 || item.ItemLoc == 5 {
+			seen := false
+			for _, fp := range filtered {
+				if fp.Match(levelNo, item) {
+					seen = true
+					break
+				}
+			}
+			if !seen {
+				unfiltered = append(unfiltered, item)
+			}
+		}
+	}
+
+	filteredNext := append(make([]types.ItemFootprint, 0, len(filtered)+len(unfiltered)), filtered...)
+	displayedNext := make([]types.ItemFootprint, 0, len(displayed))
+
+	// Items displayed before are kept while they are on the ground
+	for _, displayedItem := range displayed {
+		for _, item := range items {
+			if displayedItem.Match(levelNo, item) {
+				displayedNext = append(displayedNext, displayedItem)
+				break
+			}
+		}
+	}
+
+	for _, item := range unfiltered {
+		footprint := types.ItemFootprint{
+			Area:     levelNo,
+			Position: image.Point{X: item.ItemX, Y: item.ItemY},
+			Name:     item.Name,
+			Quality:  item.QualityNo,
+		}
+		// Items matching a NIP rule are displayed
+		if item.Filter() {
+			displayedNext = append(displayedNext, footprint)
+		}
+		filteredNext = append(filteredNext, footprint)
+	}
+	return displayedNext, filteredNext
+}
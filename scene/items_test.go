@@ -0,0 +1,79 @@
+// scene/items_test.go
+
+package scene
+
+import (
+	"image"
+	"slices"
+	"testing"
+
+	"GalyMap/globals"
+	"GalyMap/types"
+)
+
+func TestDisplayedItems(t *testing.T) {
+	footprint := func(item types.Item) types.ItemFootprint {
+		return types.ItemFootprint{Area: 2, Position: image.Point{X: item.ItemX, Y: item.ItemY}, Name: item.Name, Quality: item.QualityNo}
+	}
+	ring := types.Item{Name: "Ring", ItemLoc: 3, ItemX: 5010, ItemY: 5004, QualityNo: 4}
+	axe := types.Item{Name: "Axe", ItemLoc: 5, ItemX: 5012, ItemY: 5008, QualityNo: 2}
+	helm := types.Item{Name: "Cap", ItemLoc: 3, ItemX: 5020, ItemY: 5001, QualityNo: 7}
+	worn := types.Item{Name: "Boots", ItemLoc: 1, ItemX: 5012, ItemY: 5008, QualityNo: 2}
+
+	tests := []struct {
+		name          string
+		items         []types.Item
+		displayed     []types.ItemFootprint
+		filtered      []types.ItemFootprint
+		wantDisplayed []types.ItemFootprint
+		wantFiltered  []types.ItemFootprint
+	}{
+		{
+			name:         "new items on the ground are filtered once",
+			items:        []types.Item{ring, axe, worn},
+			wantFiltered: []types.ItemFootprint{footprint(ring), footprint(axe)},
+		},
+		{
+			name:         "filtered items aren't run through the rules again",
+			items:        []types.Item{ring, axe},
+			filtered:     []types.ItemFootprint{footprint(ring)},
+			wantFiltered: []types.ItemFootprint{footprint(ring), footprint(axe)},
+		},
+		{
+			name:          "displayed items stay while on the ground",
+			items:         []types.Item{ring, helm},
+			displayed:     []types.ItemFootprint{footprint(helm)},
+			filtered:      []types.ItemFootprint{footprint(ring), footprint(helm)},
+			wantDisplayed: []types.ItemFootprint{footprint(helm)},
+			wantFiltered:  []types.ItemFootprint{footprint(ring), footprint(helm)},
+		},
+		{
+			name:         "picked up items are no longer displayed",
+			items:        []types.Item{ring},
+			displayed:    []types.ItemFootprint{footprint(helm)},
+			filtered:     []types.ItemFootprint{footprint(ring), footprint(helm)},
+			wantFiltered: []types.ItemFootprint{footprint(ring), footprint(helm)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without NIP rules no new item is displayed
+			saved := types.NipRules
+			types.NipRules = nil
+			defer func() { types.NipRules = saved }()
+
+			displayed := slices.Clone(tt.displayed)
+			filtered := slices.Clone(tt.filtered)
+			gotDisplayed, gotFiltered := DisplayedItems(&globals.GameState{LevelNo: 2, Items: tt.items}, displayed, filtered)
+			if !slices.Equal(gotDisplayed, tt.wantDisplayed) {
+				t.Errorf("displayed %v, want %v", gotDisplayed, tt.wantDisplayed)
+			}
+			if !slices.Equal(gotFiltered, tt.wantFiltered) {
+				t.Errorf("filtered %v, want %v", gotFiltered, tt.wantFiltered)
+			}
+			if !slices.Equal(displayed, tt.displayed) || !slices.Equal(filtered, tt.filtered) {
+				t.Errorf("DisplayedItems changed the lists it was given")
+			}
+		})
+	}
+}
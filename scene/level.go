@@ -0,0 +1,69 @@
+// scene/level.go
+
+package scene
+
+import (
+	"image"
+	"image/color"
+
+	"GalyMap/mapdata"
+)
+
+var (
+	// Cell colors of the automap layer
+	walkableColor = color.NRGBA{R: 120, G: 120, B: 140, A: 60}
+	wallColor     = color.NRGBA{R: 220, G: 220, B: 230, A: 170}
+)
+
+// Level is the collision map of the player's level, drawn as a layer under
+// the sprites, along with its markers and the grid routes are planned on
+type Level struct {
+	Image   *image.NRGBA
+	Origin  image.Point // game coordinates of the top left cell
+	Markers []mapdata.Marker
+	Grid    *mapdata.CollisionGrid
+}
+
+// NewLevel decodes the collision map of level and draws its layer.
+func NewLevel(level *mapdata.Level) *Level {
+	grid := level.Collision()
+	return &Level{
+		Image:   levelImage(grid),
+		Origin:  image.Point{X: level.Offset.X, Y: level.Offset.Y},
+		Markers: level.Markers(),
+		Grid:    grid,
+	}
+}
+
+// levelImage draws walkable cells faintly and blocked cells next to them as
+// walls, leaving the rest of the level transparent. Colors are not
+// premultiplied, matching the overlay's blend function.
+func levelImage(grid *mapdata.CollisionGrid) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, max(grid.Width, 1), max(grid.Height, 1)))
+	for y := 0; y < grid.Height; y++ {
+		for x := 0; x < grid.Width; x++ {
+			switch {
+			case grid.At(x, y):
+				img.Set(x, y, walkableColor)
+			case grid.At(x-1, y) || grid.At(x+1, y) || grid.At(x, y-1) || grid.At(x, y+1):
+				img.Set(x, y, wallColor)
+			}
+		}
+	}
+	return img
+}
+
+// addLevel queues the level layer. The image is a rectangle in game
+// coordinates, so its corners through the isometric transform are all that
+// is needed to draw it rotated.
+func (c *composer) addLevel(level *Level) {
+	size := level.Image.Bounds().Size()
+	x0, y0 := float64(level.Origin.X), float64(level.Origin.Y)
+	x1, y1 := x0+float64(size.X), y0+float64(size.Y)
+	var corners [4][2]float32
+	for i, corner := range [4][2]float64{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}} {
+		x, y := c.view.GameToScreenPoint(corner[0], corner[1], c.state.Pos)
+		corners[i] = [2]float32{float32(x), float32(y)}
+	}
+	c.frame.AddLayer(level.Image, corners, c.clip())
+}
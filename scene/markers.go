@@ -0,0 +1,109 @@
+// scene/markers.go
+
+package scene
+
+import (
+	"image/color"
+	"math"
+
+	"GalyMap/globals"
+	"GalyMap/mapdata"
+	"GalyMap/render"
+)
+
+const (
+	markerSize = 14
+	arrowSize  = 24
+	edgeMargin = 40 // distance of the edge arrows from the overlay border
+)
+
+var (
+	markerColors = map[mapdata.MarkerKind]color.NRGBA{
+		mapdata.MarkerExit:     {R: 200, G: 120, B: 255, A: 230},
+		mapdata.MarkerWaypoint: {R: 80, G: 170, B: 255, A: 230},
+		mapdata.MarkerQuest:    {R: 255, G: 215, B: 0, A: 230},
+	}
+	markerShapes = map[mapdata.MarkerKind]string{
+		mapdata.MarkerExit:     render.ShapeSquare,
+		mapdata.MarkerWaypoint: render.ShapeDiamond,
+		mapdata.MarkerQuest:    render.ShapeDot,
+	}
+)
+
+// Markers merges the markers of the level presets, when level is the
+// player's, with the marker objects read from memory, which are all there is
+// without a map server.
+func Markers(state *globals.GameState, level *Level) []mapdata.Marker {
+	var markers []mapdata.Marker
+	seen := make(map[string]bool)
+	if level != nil {
+		for _, marker := range level.Markers {
+			markers = append(markers, marker)
+			if marker.Kind != mapdata.MarkerExit {
+				seen[marker.Label] = true
+			}
+		}
+	}
+	for _, object := range state.Objects {
+		marker, ok := mapdata.ObjectMarker(int(object.TxtFileNo), int(object.Pos.X), int(object.Pos.Y))
+		if ok && !seen[marker.Label] {
+			markers = append(markers, marker)
+			seen[marker.Label] = true
+		}
+	}
+	return markers
+}
+
+// addMarkers queues the exits, waypoints and quest objects of the player's
+// level, with an arrow at the edge of the overlay for those out of range.
+func (c *composer) addMarkers(markers []mapdata.Marker) {
+	for _, marker := range markers {
+		x, y := float64(marker.X), float64(marker.Y)
+		if c.view.IsWithinVisibleRange(x, y, c.state.Pos) {
+			screenX, screenY := c.view.GameToScreenPoint(x, y, c.state.Pos)
+			size := c.view.UISize(markerSize)
+			c.frame.Add(markerShapes[marker.Kind], float32(screenX), float32(screenY), float32(size), float32(size), markerColors[marker.Kind])
+			c.queueLabel(marker.Label, screenX, screenY-size/2-c.view.UISize(labelGap), true, labelColor)
+		} else {
+			c.addEdgeArrow(marker)
+		}
+	}
+}
+
+// addEdgeArrow queues an arrow at the edge of the overlay pointing from the player towards marker.
+func (c *composer) addEdgeArrow(marker mapdata.Marker) {
+	playerPos := c.state.Pos
+	centerX, centerY := c.view.GameToScreenPoint(playerPos.X, playerPos.Y, playerPos)
+	targetX, targetY := c.view.GameToScreenPoint(float64(marker.X), float64(marker.Y), playerPos)
+	dx, dy := targetX-centerX, targetY-centerY
+	if dx == 0 && dy == 0 {
+		return
+	}
+
+	// Walk from the player towards the target until the margin around the viewport border
+	viewport := c.view.viewport()
+	margin := c.view.UISize(edgeMargin)
+	reachX := math.Max(centerX-viewport.X, viewport.X+viewport.Width-centerX) - margin
+	reachY := math.Max(centerY-viewport.Y, viewport.Y+viewport.Height-centerY) - margin
+	t := math.Min(reachX/math.Abs(dx), reachY/math.Abs(dy))
+	if t >= 1 {
+		// The target is on screen after all, draw the arrow next to it
+		t = 1
+	}
+	arrowX, arrowY := centerX+dx*t, centerY+dy*t
+
+	// Rotate the arrow, which points right in its atlas cell, towards the target
+	angle := math.Atan2(dy, dx)
+	size := c.view.UISize(arrowSize)
+	c.frame.AddRotated(render.ShapeArrow, float32(arrowX), float32(arrowY), float32(size), float32(size), float32(angle), markerColors[marker.Kind])
+
+	// Put the label on the side of the arrow facing the player
+	gap := size/2 + c.view.UISize(labelGap)
+	labelY := arrowY + gap
+	above := false
+	if dy > 0 {
+		labelY = arrowY - gap
+		above = true
+	}
+	c.queueLabel(marker.Label, arrowX, labelY, above, labelColor)
+}
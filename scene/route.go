@@ -0,0 +1,111 @@
+// scene/route.go
+
+package scene
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"GalyMap/globals"
+	"GalyMap/mapdata"
+	"GalyMap/pathfinding"
+	"GalyMap/render"
+)
+
+const (
+	dotSpacing = 3 // game units between the dots of the route
+	dotSize    = 6
+)
+
+var routeColor = color.NRGBA{R: 120, G: 255, B: 120, A: 220}
+
+// RouteTarget resolves target, a lower case route target setting, in the
+// player's level. "exit" picks the exit to the next level, "waypoint" the
+// waypoint, and any other name a super unique monster or a marker of that name.
+func RouteTarget(state *globals.GameState, markers []mapdata.Marker, target string) (mapdata.Marker, bool) {
+	switch target {
+	case "":
+		return mapdata.Marker{}, false
+	case "exit":
+		return nextExit(markers, int(state.LevelNo), state.Pos)
+	case "waypoint":
+		for _, marker := range markers {
+			if marker.Kind == mapdata.MarkerWaypoint {
+				return marker, true
+			}
+		}
+		return mapdata.Marker{}, false
+	}
+
+	for _, mob := range state.Mobs {
+		if mob.SuperUnique != "" && strings.ToLower(mob.SuperUnique) == target {
+			return mapdata.Marker{Label: mob.SuperUnique, X: int(mob.Pos.X), Y: int(mob.Pos.Y)}, true
+		}
+	}
+	for _, marker := range markers {
+		if strings.ToLower(marker.Label) == target {
+			return marker, true
+		}
+	}
+	return mapdata.Marker{}, false
+}
+
+// PlanRoute searches for a route from start to goal, in game coordinates, over the grid of level.
+func PlanRoute(level *Level, start, goal image.Point, opts pathfinding.Options) ([]image.Point, error) {
+	path, err := pathfinding.FindPath(level.Grid, start.Sub(level.Origin), goal.Sub(level.Origin), opts)
+	points := make([]image.Point, len(path))
+	for i, p := range path {
+		points[i] = p.Add(level.Origin)
+	}
+	return points, err
+}
+
+// nextExit prefers the exit to the following level, then the exit the map
+// server marks as the right one, then the closest exit.
+func nextExit(markers []mapdata.Marker, levelNo int, playerPos globals.UnitPosition) (mapdata.Marker, bool) {
+	var best mapdata.Marker
+	bestRank, bestDistance := -1, math.Inf(1)
+	for _, marker := range markers {
+		if marker.Kind != mapdata.MarkerExit {
+			continue
+		}
+		rank := 0
+		switch {
+		case marker.TargetLevel == levelNo+1:
+			rank = 2
+		case marker.IsGoodExit:
+			rank = 1
+		}
+		d := math.Hypot(float64(marker.X)-playerPos.X, float64(marker.Y)-playerPos.Y)
+		if rank > bestRank || (rank == bestRank && d < bestDistance) {
+			best, bestRank, bestDistance = marker, rank, d
+		}
+	}
+	return best, bestRank >= 0
+}
+
+// addDottedLine queues dots every dotSpacing game units along points.
+func (c *composer) addDottedLine(points []image.Point) {
+	size := float32(c.view.UISize(dotSize))
+	viewport := c.view.viewport()
+	carry := 0.0 // distance left over from the previous segment
+	for i := 1; i < len(points); i++ {
+		ax, ay := float64(points[i-1].X), float64(points[i-1].Y)
+		bx, by := float64(points[i].X), float64(points[i].Y)
+		length := math.Hypot(bx-ax, by-ay)
+		for d := carry; d < length; d += dotSpacing {
+			x, y := ax+(bx-ax)*d/length, ay+(by-ay)*d/length
+			screenX, screenY := c.view.GameToScreenPoint(x, y, c.state.Pos)
+			if screenX < viewport.X || screenY < viewport.Y || screenX > viewport.X+viewport.Width || screenY > viewport.Y+viewport.Height {
+				continue
+			}
+			c.frame.Add(render.ShapeDot, float32(screenX), float32(screenY), size, size, routeColor)
+		}
+		carry = math.Mod(carry-length, dotSpacing)
+		if carry < 0 {
+			carry += dotSpacing
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// scene/scene.go
+
+package scene
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"GalyMap/globals"
+	"GalyMap/render"
+	"GalyMap/types"
+)
+
+const maxLabelLength = 48
+
+var (
+	outlineColor = color.NRGBA{A: 200}
+	labelColor   = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+
+	// solidImages are the single pixel layers of the viewport backgrounds,
+	// kept so that renderers upload each of them once
+	solidImagesMu sync.Mutex
+	solidImages   = make(map[color.NRGBA]*image.NRGBA)
+)
+
+// Scene is everything the overlay shows for one game state
+type Scene struct {
+	State *globals.GameState
+	Level *Level                // nil when the level map isn't loaded
+	Route []image.Point         // in game coordinates, empty for none
+	Items []types.ItemFootprint // items picked by the NIP rules, see DisplayedItems
+
+	// Settings switch layers of entities on and off like globals.Settings, everything is shown when nil
+	Settings map[string]bool
+}
+
+// composer queues the parts of a scene into a frame
+type composer struct {
+	frame    *render.Frame
+	assets   *Assets
+	view     View
+	state    *globals.GameState
+	settings map[string]bool
+	labels   []queuedLabel
+}
+
+// queuedLabel is a label placed while composing, added on top of the frame once everything else is
+type queuedLabel struct {
+	text  string
+	x, y  float64
+	above bool
+	color color.NRGBA
+}
+
+// Compose queues the scene into frame: the viewport background, the level
+// map, the route, the entities, the markers, their labels and the info panel,
+// in that order. Nothing is drawn while the game state is empty or a menu is open.
+func Compose(frame *render.Frame, assets *Assets, view View, s *Scene) {
+	if s.State == nil || s.State.Version == 0 || s.State.MenuShown {
+		return
+	}
+	c := &composer{frame: frame, assets: assets, view: view, state: s.State, settings: s.Settings}
+	c.addBackground()
+	if s.Level != nil {
+		c.addLevel(s.Level)
+	}
+	c.addDottedLine(s.Route)
+	c.addSprites(c.collectSprites(s.Items))
+	c.addMarkers(Markers(s.State, s.Level))
+	c.addLabels()
+	c.addInfoPanel()
+}
+
+// Render composes the scene and draws it on the CPU into a new image the size of view.
+func Render(assets *Assets, view View, s *Scene) *image.RGBA {
+	frame := render.NewFrame(assets.Atlas)
+	Compose(frame, assets, view, s)
+	renderer := render.NewSoftwareRenderer(int(view.Width), int(view.Height))
+	renderer.Render(frame)
+	return renderer.Image
+}
+
+// shown reports whether the layer switched by setting is on. Unknown settings are on.
+func (c *composer) shown(setting string) bool {
+	value, ok := c.settings[setting]
+	return !ok || value
+}
+
+// clip returns the viewport in whole pixels, which layers are cut to.
+func (c *composer) clip() image.Rectangle {
+	viewport := c.view.viewport()
+	return image.Rect(int(viewport.X), int(viewport.Y), int(viewport.X+viewport.Width), int(viewport.Y+viewport.Height))
+}
+
+// addBackground fills the viewport with the background of the view, if any.
+// It is a layer rather than an instance so that the level map is drawn over it.
+func (c *composer) addBackground() {
+	if c.view.Background.A == 0 {
+		return
+	}
+	solidImagesMu.Lock()
+	img, ok := solidImages[c.view.Background]
+	if !ok {
+		img = image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		img.SetNRGBA(0, 0, c.view.Background)
+		solidImages[c.view.Background] = img
+	}
+	solidImagesMu.Unlock()
+
+	r := c.clip()
+	corners := [4][2]float32{
+		{float32(r.Min.X), float32(r.Min.Y)},
+		{float32(r.Max.X), float32(r.Min.Y)},
+		{float32(r.Max.X), float32(r.Max.Y)},
+		{float32(r.Min.X), float32(r.Max.Y)},
+	}
+	c.frame.AddLayer(img, corners, r)
+}
+
+// queueLabel places text to be added on top of the frame by addLabels.
+func (c *composer) queueLabel(text string, x, y float64, above bool, textColor color.NRGBA) {
+	c.labels = append(c.labels, queuedLabel{text: text, x: x, y: y, above: above, color: textColor})
+}
+
+// addLabels adds the queued labels after everything else so that they stay readable.
+func (c *composer) addLabels() {
+	for _, label := range c.labels {
+		c.addLabel(label)
+	}
+	c.labels = c.labels[:0]
+}
+
+// truncateLabel cuts text to maxLabelLength characters, never inside a UTF-8 sequence.
+func truncateLabel(text string) string {
+	count := 0
+	for i := range text {
+		if count == maxLabelLength {
+			return text[:i]
+		}
+		count++
+	}
+	return text
+}
+
+// addLabel adds text centered on x, with its bottom edge at y when above is
+// set and its top edge at y otherwise, kept inside the viewport.
+func (c *composer) addLabel(label queuedLabel) {
+	text := truncateLabel(label.text)
+	textWidth, textHeight := c.assets.Font.Measure(text)
+	w, h := float64(textWidth), float64(textHeight)
+	viewport := c.view.viewport()
+	left := math.Max(viewport.X, math.Min(viewport.X+viewport.Width-w, label.x-w/2))
+	top := label.y
+	if label.above {
+		top = label.y - h
+	}
+	top = math.Max(viewport.Y, math.Min(viewport.Y+viewport.Height-h, top))
+	c.assets.Font.AddText(c.frame, text, float32(left), float32(top), render.TextStyle{Color: label.color, Outline: outlineColor})
+}
@@ -0,0 +1,32 @@
+// scene/scene_test.go
+
+package scene
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateLabel(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Tal Rasha's Tomb", "Tal Rasha's Tomb"},
+		{strings.Repeat("a", maxLabelLength), strings.Repeat("a", maxLabelLength)},
+		{strings.Repeat("a", maxLabelLength+5), strings.Repeat("a", maxLabelLength)},
+		{strings.Repeat("ä", maxLabelLength+5), strings.Repeat("ä", maxLabelLength)},
+		{strings.Repeat("a", maxLabelLength-1) + "伊瑞蒂", strings.Repeat("a", maxLabelLength-1) + "伊"},
+		{strings.Repeat("地", 30), strings.Repeat("地", 30)},
+	}
+	for _, tt := range tests {
+		got := truncateLabel(tt.text)
+		if got != tt.want {
+			t.Errorf("truncateLabel(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateLabel(%q) = %q is not valid UTF-8", tt.text, got)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// scene/sprites.go
+
+package scene
+
+import (
+	"GalyMap/globals"
+	"GalyMap/render"
+	"GalyMap/types"
+)
+
+const labelGap = 4 // space between an entity or a marker and its label
+
+// kindSettings are the settings switching each sprite kind on and off
+var kindSettings = map[string]string{
+	render.SpriteMob:         "showNormalMobs",
+	render.SpriteMinion:      "showNormalMobs",
+	render.SpriteTownNPC:     "showNormalMobs",
+	render.SpriteChampion:    "showUniqueMobs",
+	render.SpriteUnique:      "showUniqueMobs",
+	render.SpriteSuperUnique: "showUniqueMobs",
+	render.SpriteBoss:        "showBosses",
+	render.SpriteCorpse:      "showDeadMobs",
+	render.SpritePortal:      "showPortals",
+	render.SpriteShrine:      "showShrines",
+	render.SpriteChest:       "showChests",
+}
+
+// sprite is an entity to draw at a position in game coordinates in the style
+// of its kind, with an optional label underneath
+type sprite struct {
+	Position globals.UnitPosition
+	Kind     string
+	Label    string
+}
+
+// collectSprites lists the entities around the player, ending with the player
+// so that it is drawn on top.
+func (c *composer) collectSprites(items []types.ItemFootprint) []sprite {
+	state := c.state
+	sprites := make([]sprite, 0, len(state.Mobs)+len(state.Objects)+len(items)+1)
+	playerPos := state.Pos
+
+	// Add portals labeled with their owner, shrines labeled with their type, and chests
+	for _, object := range state.Objects {
+		kind := render.ObjectSprite(object)
+		pos := globals.UnitPosition{X: float64(object.Pos.X), Y: float64(object.Pos.Y)}
+		if kind != "" && c.shown(kindSettings[kind]) && c.view.IsWithinVisibleRange(pos.X, pos.Y, playerPos) {
+			label := ""
+			switch kind {
+			case render.SpritePortal:
+				label = object.OwnerName
+			case render.SpriteShrine:
+				label = object.ShrineType
+			}
+			sprites = append(sprites, sprite{Position: pos, Kind: kind, Label: label})
+		}
+	}
+
+	// Add mobs relative to player position
+	for _, mob := range state.Mobs {
+		// Only render mobs within visible range
+		if c.view.IsWithinVisibleRange(mob.Pos.X, mob.Pos.Y, playerPos) {
+			// Minions' stats aren't read, so their HP is always zero
+			kind := render.MobSprite(mob)
+			if (mob.IsCorpse || mob.IsPlayerMinion || mob.HP > 0) && c.shown(kindSettings[kind]) {
+				sprites = append(sprites, sprite{Position: mob.Pos, Kind: kind, Label: mobLabel(mob)})
+			}
+		}
+	}
+
+	// Add Display Items
+	if !c.shown("enableItemFilter") {
+		items = nil
+	}
+	for _, item := range items {
+		pos := globals.UnitPosition{X: float64(item.Position.X), Y: float64(item.Position.Y)}
+		if c.view.IsWithinVisibleRange(pos.X, pos.Y, playerPos) {
+			sprites = append(sprites, sprite{Position: pos, Kind: render.ItemSprite(item.Quality), Label: item.Name})
+		}
+	}
+
+	// Add missiles
+	missileSettings := []string{"showPlayerMissiles", "showEnemyMissiles"}
+	for i, missiles := range [][]globals.Missile{state.PlayerMissiles, state.EnemyMissiles} {
+		if !c.shown(missileSettings[i]) {
+			continue
+		}
+		for _, missile := range missiles {
+			if c.view.IsWithinVisibleRange(missile.Pos.X, missile.Pos.Y, playerPos) {
+				sprites = append(sprites, sprite{Position: missile.Pos, Kind: render.MissileSprite(missile)})
+			}
+		}
+	}
+
+	// Add other players, telling party members apart
+	for _, player := range state.OtherPlayers {
+		if c.shown("showOtherPlayers") && c.view.IsWithinVisibleRange(player.Pos.X, player.Pos.Y, playerPos) {
+			sprites = append(sprites, sprite{Position: player.Pos, Kind: render.PlayerSprite(player, state.UnitId, state.PartyList)})
+		}
+	}
+
+	// Add player sprite at the center
+	sprites = append(sprites, sprite{Position: playerPos, Kind: render.SpritePlayer})
+	return sprites
+}
+
+// mobLabel names bosses and super uniques, and leaves other monsters unlabeled.
+func mobLabel(mob globals.Mob) string {
+	switch {
+	case mob.IsCorpse:
+		return ""
+	case mob.IsBoss:
+		return mob.TextTitle
+	default:
+		return mob.SuperUnique
+	}
+}
+
+// addSprites queues sprites in the style of their kind, and their labels in the color of the kind.
+func (c *composer) addSprites(sprites []sprite) {
+	for _, sprite := range sprites {
+		style, ok := c.assets.Styles[sprite.Kind]
+		if !ok {
+			continue
+		}
+		x, y := c.view.GameToScreenCoordinates(sprite.Position.X, sprite.Position.Y, c.state.Pos)
+		w, h := c.view.UISize(float64(style.Size.X)), c.view.UISize(float64(style.Size.Y))
+		c.frame.Add(style.Cell, float32(x), float32(y), float32(w), float32(h), style.Color)
+		if sprite.Label != "" {
+			textColor := style.Color
+			textColor.A = 255
+			c.queueLabel(sprite.Label, float64(x), float64(y)+h/2+c.view.UISize(labelGap), false, textColor)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// scene/toast.go
+
+package scene
+
+import (
+	"image/color"
+	"time"
+
+	"GalyMap/render"
+)
+
+const (
+	toastDuration = 2 * time.Second
+	toastFade     = 500 * time.Millisecond // at the end of toastDuration
+	toastTop      = 80                     // distance of the toast from the top of the overlay
+	toastPadding  = 8
+)
+
+var toastBackground = color.NRGBA{A: 180}
+
+// Toaster holds a brief message confirming a change, such as a layer toggled by a hotkey
+type Toaster struct {
+	text  string
+	shown time.Time
+}
+
+// Show replaces the message with text from now on.
+func (t *Toaster) Show(text string, now time.Time) {
+	t.text, t.shown = text, now
+}
+
+// Current returns the message and its opacity, which fades out at the end,
+// or false once it expired.
+func (t *Toaster) Current(now time.Time) (string, float64, bool) {
+	age := now.Sub(t.shown)
+	if t.text == "" || age >= toastDuration {
+		return "", 0, false
+	}
+	opacity := 1.0
+	if left := toastDuration - age; left < toastFade {
+		opacity = float64(left) / float64(toastFade)
+	}
+	return t.text, opacity, true
+}
+
+// ComposeToast queues text centered at the top of the overlay on a dark
+// panel, at opacity. It is drawn whatever the display mode, even hidden.
+func ComposeToast(frame *render.Frame, assets *Assets, view View, text string, opacity float64) {
+	font := assets.Font
+	textWidth, textHeight := font.Measure(text)
+	padding := float32(view.UISize(toastPadding))
+	w, h := textWidth+2*padding, textHeight+2*padding
+	x, y := float32(view.Width)/2, float32(view.UISize(toastTop))+h/2
+
+	fade := func(c color.NRGBA) color.NRGBA {
+		c.A = uint8(float64(c.A) * opacity)
+		return c
+	}
+	frame.Add(render.CellSolid, x, y, w, h, fade(toastBackground))
+	font.AddText(frame, text, x-textWidth/2, y-textHeight/2, render.TextStyle{Color: fade(labelColor), Shadow: fade(outlineColor)})
+}
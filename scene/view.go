@@ -0,0 +1,123 @@
+// scene/view.go
+
+package scene
+
+import (
+	"image/color"
+	"math"
+
+	"GalyMap/globals"
+)
+
+// referenceHeight is the client height the default view is tuned for
+const referenceHeight = 1080
+
+// View is how game coordinates map onto the overlay: an isometric projection
+// around the player, who is always at the center of the viewport
+type View struct {
+	Width, Height    float64     // overlay size in pixels
+	Viewport         Viewport    // area of the overlay the map is drawn in, the whole overlay when zero
+	Background       color.NRGBA // fills the viewport under the map, none when transparent
+	Scale            float64     // pixels per game unit
+	OffsetX, OffsetY float64     // nudge of the center so that it lines up with the game's own
+	UIScale          float64     // size of the icons and the text relative to 1080p, 1 when zero
+}
+
+// Viewport is a rectangle of the overlay in pixels
+type Viewport struct {
+	X, Y, Width, Height float64
+}
+
+// DefaultView is the view of a 1920x1080 overlay over the game at its default zoom.
+func DefaultView() View {
+	return View{Width: 1920, Height: 1080, Scale: 4.6, OffsetX: 2, OffsetY: -7, UIScale: 1}
+}
+
+// ViewForWindow returns the view of an overlay covering a game client area of
+// width by height physical pixels. D2R scales the world and its UI with the
+// height of the client area and shows more of the level on the sides of wider
+// screens, so the default view is scaled by the height alone and stays
+// centered: 1440p draws a third larger, 4K twice as large, and ultrawide
+// screens draw at the scale of their height.
+func ViewForWindow(width, height int) View {
+	v := DefaultView()
+	if width <= 0 || height <= 0 {
+		return v
+	}
+	scale := float64(height) / referenceHeight
+	return View{
+		Width:   float64(width),
+		Height:  float64(height),
+		Scale:   v.Scale * scale,
+		OffsetX: v.OffsetX * scale,
+		OffsetY: v.OffsetY * scale,
+		UIScale: scale,
+	}
+}
+
+// viewport returns the area the map is drawn in.
+func (v View) viewport() Viewport {
+	if v.Viewport.Width <= 0 || v.Viewport.Height <= 0 {
+		return Viewport{Width: v.Width, Height: v.Height}
+	}
+	return v.Viewport
+}
+
+// UISize scales a size in pixels at 1080p, of an icon or a margin, to the overlay.
+func (v View) UISize(size float64) float64 {
+	if v.UIScale == 0 {
+		return size
+	}
+	return size * v.UIScale
+}
+
+// IsWithinVisibleRange reports whether a position is close enough to the player to be drawn in the viewport.
+func (v View) IsWithinVisibleRange(x, y float64, playerPos globals.UnitPosition) bool {
+	screenX, screenY := v.GameToScreenPoint(x, y, playerPos)
+	viewport := v.viewport()
+	return screenX >= viewport.X && screenX <= viewport.X+viewport.Width &&
+		screenY >= viewport.Y && screenY <= viewport.Y+viewport.Height
+}
+
+// GameToScreenCoordinates transforms game coordinates into screen coordinates
+// while keeping the player centered, clamped to the viewport.
+func (v View) GameToScreenCoordinates(gameX, gameY float64, playerPos globals.UnitPosition) (int, int) {
+	screenX, screenY := v.GameToScreenPoint(gameX, gameY, playerPos)
+
+	// Bounds checking to ensure coordinates stay within the viewport
+	viewport := v.viewport()
+	screenX = math.Max(viewport.X, math.Min(viewport.X+viewport.Width, screenX))
+	screenY = math.Max(viewport.Y, math.Min(viewport.Y+viewport.Height, screenY))
+
+	return int(screenX), int(screenY)
+}
+
+// GameToScreenPoint applies the isometric transform of GameToScreenCoordinates
+// without clamping to the screen, for shapes that extend past its edges.
+func (v View) GameToScreenPoint(gameX, gameY float64, playerPos globals.UnitPosition) (float64, float64) {
+	// Calculate the relative position from player
+	relativeX := gameX - playerPos.X
+	relativeY := gameY - playerPos.Y
+
+	const (
+		// Isometric rotation angle (45 degrees)
+		angleRadians = math.Pi / 4
+		// Y-axis compression factor for isometric view
+		yCompression = 0.5
+	)
+
+	// Apply isometric rotation
+	rotatedX := relativeX*math.Cos(angleRadians) - relativeY*math.Sin(angleRadians)
+	rotatedY := relativeX*math.Sin(angleRadians) + relativeY*math.Cos(angleRadians)
+
+	// Apply scaling and Y compression
+	scaledX := rotatedX * v.Scale
+	scaledY := rotatedY * v.Scale * yCompression
+
+	// Convert to screen coordinates (player is always at the center of the viewport)
+	viewport := v.viewport()
+	screenX := viewport.X + math.Floor(viewport.Width/2) + scaledX + v.OffsetX
+	screenY := viewport.Y + math.Floor(viewport.Height/2) + scaledY + v.OffsetY
+
+	return screenX, screenY
+}
@@ -0,0 +1,63 @@
+// scene/view_test.go
+
+package scene
+
+import (
+	"math"
+	"testing"
+
+	"GalyMap/globals"
+)
+
+func TestViewForWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		// width and height are the client area in logical pixels, which the
+		// DPI aware overlay sees multiplied by dpiScale
+		width, height int
+		dpiScale      float64
+		// want is the view as laid out by hand: the overlay size, the player
+		// at the center of the viewport nudged by the offsets, and the scales
+		wantWidth, wantHeight float64
+		wantCenterX           float64
+		wantCenterY           float64
+		wantScale             float64
+		wantUIScale           float64
+	}{
+		{"1080p", 1920, 1080, 1, 1920, 1080, 962, 533, 4.6, 1},
+		{"720p", 1280, 720, 1, 1280, 720, 641.3333, 355.3333, 3.0667, 0.6667},
+		{"1440p", 2560, 1440, 1, 2560, 1440, 1282.6667, 710.6667, 6.1333, 1.3333},
+		{"4K", 3840, 2160, 1, 3840, 2160, 1924, 1066, 9.2, 2},
+		{"ultrawide 1440p", 3440, 1440, 1, 3440, 1440, 1722.6667, 710.6667, 6.1333, 1.3333},
+		{"1080p at 125%", 1920, 1080, 1.25, 2400, 1350, 1202.5, 666.25, 5.75, 1.25},
+		{"1080p at 150%", 1920, 1080, 1.5, 2880, 1620, 1443, 799.5, 6.9, 1.5},
+		{"1080p at 200%", 1920, 1080, 2, 3840, 2160, 1924, 1066, 9.2, 2},
+		{"800p at 125%", 1280, 800, 1.25, 1600, 1000, 801.8519, 493.5185, 4.2593, 0.9259},
+		{"minimized", 0, 0, 1, 1920, 1080, 962, 533, 4.6, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width := int(math.Round(float64(tt.width) * tt.dpiScale))
+			height := int(math.Round(float64(tt.height) * tt.dpiScale))
+			v := ViewForWindow(width, height)
+
+			viewport := v.viewport()
+			if viewport.X != 0 || viewport.Y != 0 || viewport.Width != tt.wantWidth || viewport.Height != tt.wantHeight {
+				t.Errorf("viewport %+v, want %gx%g at the origin", viewport, tt.wantWidth, tt.wantHeight)
+			}
+			player := globals.UnitPosition{X: 5012, Y: 5008}
+			x, y := v.GameToScreenPoint(player.X, player.Y, player)
+			if !near(x, tt.wantCenterX) || !near(y, tt.wantCenterY) {
+				t.Errorf("player at %.4f, %.4f, want %.4f, %.4f", x, y, tt.wantCenterX, tt.wantCenterY)
+			}
+			if !near(v.Scale, tt.wantScale) || !near(v.UIScale, tt.wantUIScale) {
+				t.Errorf("scale %.4f and UI scale %.4f, want %.4f and %.4f", v.Scale, v.UIScale, tt.wantScale, tt.wantUIScale)
+			}
+		})
+	}
+}
+
+// near reports whether got is within the rounding of the values in the tables
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-3
+}
@@ -0,0 +1,51 @@
+// ui/display.go
+package ui
+
+import (
+	"log"
+
+	"GalyMap/config"
+	"GalyMap/scene"
+)
+
+var (
+	// display is the mode and zooms the overlay is shown in, owned by the render loop
+	display scene.Display
+	// settings are saved back to config.SettingsFile when the display or a layer changes
+	settings *config.Settings
+)
+
+// initDisplay sets the display from the settings.
+func initDisplay(cfg *config.Settings) {
+	settings = cfg
+	display = scene.NewDisplay(cfg)
+	log.Printf("Display mode %s at zoom %g", display.Mode, display.CurrentZoom())
+}
+
+// cycleDisplayMode switches to the next display mode and saves it.
+func cycleDisplayMode() {
+	display.NextMode()
+	applyDisplay()
+}
+
+// zoomDisplay steps the zoom of the current display mode in, or out when steps is negative, and saves it.
+func zoomDisplay(steps int) {
+	display.StepZoom(steps)
+	applyDisplay()
+}
+
+// applyDisplay fits the view to the display and persists it.
+func applyDisplay() {
+	if gameWindow != nil && gameWindow.info.Width > 0 {
+		view = display.View(int(gameWindow.info.Width), int(gameWindow.info.Height))
+	}
+	display.Save(settings)
+	saveSettings()
+}
+
+// saveSettings persists the display and the layers switched at runtime.
+func saveSettings() {
+	if err := config.SaveConfig(config.SettingsFile, settings); err != nil {
+		log.Printf("Failed to save the settings: %v", err)
+	}
+}
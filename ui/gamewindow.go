@@ -0,0 +1,223 @@
+// ui/gamewindow.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"GalyMap/globals"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"golang.org/x/sys/windows"
+)
+
+// gameWindowCheckInterval is how often the game window is checked for moves and resizes
+const gameWindowCheckInterval = 250 * time.Millisecond
+
+// defaultDPI is the DPI of a monitor at 100% scaling
+const defaultDPI = 96
+
+// DPI awareness contexts are pseudo handles
+const dpiAwarenessContextPerMonitorAwareV2 = ^uintptr(3) // (DPI_AWARENESS_CONTEXT)-4
+
+var (
+	procSetProcessDpiAwarenessContext = modUser32.NewProc("SetProcessDpiAwarenessContext")
+	procSetProcessDPIAware            = modUser32.NewProc("SetProcessDPIAware")
+	procEnumWindows                   = modUser32.NewProc("EnumWindows")
+	procIsWindow                      = modUser32.NewProc("IsWindow")
+	procIsWindowVisible               = modUser32.NewProc("IsWindowVisible")
+	procGetWindowTextLength           = modUser32.NewProc("GetWindowTextLengthW")
+	procGetWindowText                 = modUser32.NewProc("GetWindowTextW")
+	procGetWindowThreadProcessId      = modUser32.NewProc("GetWindowThreadProcessId")
+	procGetClientRect                 = modUser32.NewProc("GetClientRect")
+	procClientToScreen                = modUser32.NewProc("ClientToScreen")
+	procGetDpiForWindow               = modUser32.NewProc("GetDpiForWindow")
+
+	// gameWindow is the D2R window the overlay follows, owned by the render loop
+	gameWindow *trackedWindow
+)
+
+// trackedWindow follows the client area of a D2R window as it moves, resizes
+// and switches between windowed and fullscreen, and finds the window of the
+// next process when D2R restarts.
+type trackedWindow struct {
+	process   *globals.ProcessInfo
+	info      globals.WindowInfo // zero until the window is found
+	visible   bool               // the window exists and isn't minimized
+	lastCheck time.Time
+}
+
+// setPerMonitorDPIAware makes window coordinates physical pixels on every
+// monitor, so that the overlay covers the game exactly whatever the scaling
+// of the monitor it is on. Windows before 10 1703 only get system awareness.
+func setPerMonitorDPIAware() {
+	if procSetProcessDpiAwarenessContext.Find() == nil {
+		if ok, _, _ := procSetProcessDpiAwarenessContext.Call(dpiAwarenessContextPerMonitorAwareV2); ok != 0 {
+			return
+		}
+	}
+	if procSetProcessDPIAware.Find() == nil {
+		procSetProcessDPIAware.Call()
+	}
+}
+
+func newTrackedWindow(process *globals.ProcessInfo) *trackedWindow {
+	return &trackedWindow{process: process}
+}
+
+// update checks the game window at most every gameWindowCheckInterval and
+// fits the overlay window and the view to its client area when it changed.
+func (w *trackedWindow) update(window *glfw.Window) {
+	now := time.Now()
+	if now.Sub(w.lastCheck) < gameWindowCheckInterval {
+		return
+	}
+	w.lastCheck = now
+
+	info, ok := w.find()
+	w.visible = ok && info.Width > 0 && info.Height > 0
+	if !w.visible || info == w.info {
+		return
+	}
+	w.info = info
+	w.process.Windows = []globals.WindowInfo{info}
+	log.Printf("D2R client area is %dx%d at (%d, %d), %d DPI", info.Width, info.Height, info.Left, info.Top, info.DPI)
+
+	// Cover the client area, staying on top of the game after it went fullscreen
+	hwnd := uintptr(unsafe.Pointer(window.GetWin32Window()))
+	ret, _, err := procSetWindowPos.Call(
+		hwnd,
+		HWND_TOPMOST,
+		uintptr(info.Left), uintptr(info.Top), uintptr(info.Width), uintptr(info.Height),
+		SWP_NOACTIVATE,
+	)
+	if ret == 0 {
+		log.Printf("SetWindowPos failed: %v", err)
+		return
+	}
+	// The overlay is DPI aware, so its framebuffer is the client area it was
+	// just sized to. GLFW only learns of the new size once it handles the
+	// resize message, so the framebuffer size can't be asked for yet.
+	gl.Viewport(0, 0, info.Width, info.Height)
+
+	view = display.View(int(info.Width), int(info.Height))
+	if assets != nil && assetsScale != view.UIScale {
+		if err := loadAtlas(spriteManifestFile, themeFile, view.UIScale); err != nil {
+			log.Printf("Failed to reload sprites for %dx%d: %v", info.Width, info.Height, err)
+		}
+	}
+}
+
+// find reads the client area of the game window, looking for it again when it
+// was closed: among the windows of the same process first, then those of a
+// restarted D2R.
+func (w *trackedWindow) find() (globals.WindowInfo, bool) {
+	if w.info.Handle != 0 {
+		if info, err := readWindowInfo(w.info.Handle); err == nil {
+			return info, true
+		}
+	}
+	found := findGameWindows()
+	for _, info := range found {
+		if info.PID == w.process.PID {
+			return info, true
+		}
+	}
+	if len(found) == 0 {
+		return globals.WindowInfo{}, false
+	}
+	info := found[0]
+	log.Printf("Following the D2R window of process %d", info.PID)
+	w.process.PID = info.PID
+	w.process.Title = info.Title
+	return info, true
+}
+
+var (
+	// gameWindows collects the windows found by enumGameWindows. The callback
+	// is created once since callbacks are never released and are limited in
+	// number, while the overlay looks for the game window over and over.
+	gameWindowsMu   sync.Mutex
+	gameWindows     []globals.WindowInfo
+	enumGameWindows = windows.NewCallback(func(hwnd windows.HWND, lparam uintptr) uintptr {
+		if visible, _, _ := procIsWindowVisible.Call(uintptr(hwnd)); visible == 0 {
+			return 1
+		}
+		title := windowText(uintptr(hwnd))
+		if !strings.Contains(strings.ToLower(title), "diablo ii: resurrected") {
+			return 1
+		}
+		if info, err := readWindowInfo(uintptr(hwnd)); err == nil {
+			gameWindows = append(gameWindows, info)
+		}
+		return 1
+	})
+)
+
+// findGameWindows returns the visible D2R windows of every process with their client areas.
+func findGameWindows() []globals.WindowInfo {
+	gameWindowsMu.Lock()
+	defer gameWindowsMu.Unlock()
+	gameWindows = nil
+	procEnumWindows.Call(enumGameWindows, 0)
+	return gameWindows
+}
+
+// readWindowInfo reads the client area of a window in screen coordinates and
+// the DPI of its monitor. The client area is empty while the window is
+// minimized. Coordinates are physical pixels when the process is per-monitor
+// DPI aware, as the overlay is.
+func readWindowInfo(hwnd uintptr) (globals.WindowInfo, error) {
+	if ok, _, _ := procIsWindow.Call(hwnd); ok == 0 {
+		return globals.WindowInfo{}, fmt.Errorf("window %#x no longer exists", hwnd)
+	}
+	var client windows.Rect
+	if ok, _, err := procGetClientRect.Call(hwnd, uintptr(unsafe.Pointer(&client))); ok == 0 {
+		return globals.WindowInfo{}, fmt.Errorf("GetClientRect failed: %w", err)
+	}
+	origin := struct{ X, Y int32 }{}
+	if ok, _, err := procClientToScreen.Call(hwnd, uintptr(unsafe.Pointer(&origin))); ok == 0 {
+		return globals.WindowInfo{}, fmt.Errorf("ClientToScreen failed: %w", err)
+	}
+
+	// GetDpiForWindow needs Windows 10 1607, assume 100% scaling before it
+	dpi := uint32(defaultDPI)
+	if procGetDpiForWindow.Find() == nil {
+		if value, _, _ := procGetDpiForWindow.Call(hwnd); value != 0 {
+			dpi = uint32(value)
+		}
+	}
+
+	var pid uint32
+	procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+
+	width, height := client.Right-client.Left, client.Bottom-client.Top
+	return globals.WindowInfo{
+		Handle: hwnd,
+		PID:    pid,
+		Title:  windowText(hwnd),
+		Left:   origin.X,
+		Top:    origin.Y,
+		Right:  origin.X + width,
+		Bottom: origin.Y + height,
+		Width:  width,
+		Height: height,
+		DPI:    dpi,
+	}, nil
+}
+
+// windowText returns the title of a window.
+func windowText(hwnd uintptr) string {
+	length, _, _ := procGetWindowTextLength.Call(hwnd)
+	if length == 0 {
+		return ""
+	}
+	buffer := make([]uint16, length+1)
+	procGetWindowText.Call(hwnd, uintptr(unsafe.Pointer(&buffer[0])), uintptr(length+1))
+	return windows.UTF16ToString(buffer)
+}
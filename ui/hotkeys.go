@@ -0,0 +1,122 @@
+// ui/hotkeys.go
+package ui
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"GalyMap/config"
+	"GalyMap/globals"
+	"GalyMap/hotkeys"
+	"GalyMap/scene"
+	"GalyMap/types"
+)
+
+// layerNames name the layer settings in toasts, and are the settings the hotkeys toggle
+var layerNames = map[string]string{
+	"showOtherPlayers":   "Other players",
+	"showNormalMobs":     "Normal monsters",
+	"showUniqueMobs":     "Unique monsters",
+	"showBosses":         "Bosses",
+	"showDeadMobs":       "Corpses",
+	"showPlayerMissiles": "Player missiles",
+	"showEnemyMissiles":  "Enemy missiles",
+	"enableItemFilter":   "Items",
+	"showShrines":        "Shrines",
+	"showPortals":        "Portals",
+	"showChests":         "Chests",
+}
+
+var (
+	// hotkeyDispatcher runs the actions of the hotkeys, which are received on
+	// a thread of their own and dispatched by the render loop
+	hotkeyDispatcher *hotkeys.Dispatcher
+
+	// toaster holds the message of the last hotkey, owned by the render loop
+	toaster scene.Toaster
+)
+
+// initHotkeys binds the hotkeys of the settings to their actions and starts
+// listening for them. The overlay still runs without the hotkeys that fail.
+func initHotkeys(cfg *config.Settings) {
+	actions := map[string]hotkeys.Action{
+		"cycleDisplayMode": func() string {
+			cycleDisplayMode()
+			return fmt.Sprintf("Display: %s", display.Mode)
+		},
+		"zoomIn":           func() string { return stepZoom(1) },
+		"zoomOut":          func() string { return stepZoom(-1) },
+		"reloadItemFilter": reloadItemFilter,
+	}
+	for setting, name := range layerNames {
+		actions[setting] = func() string {
+			value, ok := globals.ToggleSetting(setting)
+			if !ok {
+				return ""
+			}
+			if settings.Layers == nil {
+				settings.Layers = make(map[string]bool)
+			}
+			settings.Layers[setting] = value
+			saveSettings()
+			if value {
+				return name + ": on"
+			}
+			return name + ": off"
+		}
+	}
+
+	var err error
+	hotkeyDispatcher, err = hotkeys.NewDispatcher(cfg.Hotkeys, actions)
+	if err != nil {
+		log.Printf("Some hotkeys are not bound: %v", err)
+	}
+	combos := hotkeyDispatcher.Combos()
+	if len(combos) == 0 {
+		return
+	}
+	if err = hotkeyDispatcher.Listen(hotkeys.NewListener()); err != nil {
+		log.Printf("Some hotkeys are not available: %v", err)
+	}
+	log.Printf("Listening for %d hotkeys", len(combos))
+}
+
+// stepZoom zooms the current display mode and returns the toast for it.
+func stepZoom(steps int) string {
+	if display.Mode == scene.DisplayHidden {
+		return "Display: hidden"
+	}
+	zoomDisplay(steps)
+	return fmt.Sprintf("Zoom: %g×", display.CurrentZoom())
+}
+
+// reloadItemFilter reads the NIP rules again and forgets which items were
+// already filtered, so that the items on the ground go through the new rules.
+func reloadItemFilter() string {
+	if err := types.LoadNipRules(config.NipsFolder); err != nil {
+		log.Printf("Failed to reload NIP rules: %v", err)
+		return "Item filter reload failed"
+	}
+	globals.SetFilteredItems(nil)
+	globals.SetDisplayedItems(nil)
+	return fmt.Sprintf("Item filter reloaded: %d rules", len(types.NipRules))
+}
+
+// handleHotkeys runs the actions of the hotkeys pressed since the last frame
+// and shows their message.
+func handleHotkeys() {
+	if hotkeyDispatcher == nil {
+		return
+	}
+	for _, message := range hotkeyDispatcher.Poll() {
+		toaster.Show(message, time.Now())
+	}
+}
+
+// closeHotkeys stops listening for hotkeys, releasing them for other applications.
+func closeHotkeys() {
+	if hotkeyDispatcher != nil {
+		hotkeyDispatcher.Close()
+	}
+}
@@ -0,0 +1,110 @@
+// ui/levelmap.go
+package ui
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"GalyMap/config"
+	"GalyMap/globals"
+	"GalyMap/mapdata"
+	"GalyMap/scene"
+)
+
+// levelRetryInterval is how long to wait before asking the map server again for a level that failed
+const levelRetryInterval = 30 * time.Second
+
+var (
+	mapClient *mapdata.Client
+
+	// Level fetched in the background, handed to the render loop
+	levelMu      sync.Mutex
+	levelWanted  levelKey
+	levelFetched *mapdata.Level
+	levelFailed  map[levelKey]time.Time
+
+	// Level currently shown, owned by the render loop
+	levelShown levelKey
+	shownLevel *scene.Level
+)
+
+type levelKey struct {
+	seed       uint32
+	difficulty uint16
+	levelNo    uint32
+}
+
+// currentLevelKey identifies the level the player is in
+func currentLevelKey(state *globals.GameState) levelKey {
+	return levelKey{seed: state.MapSeed, difficulty: state.Difficulty, levelNo: state.LevelNo}
+}
+
+// initLevelMap creates the map server client for the level map layer.
+func initLevelMap(cfg *config.Settings) {
+	levelFailed = make(map[levelKey]time.Time)
+	if cfg.MapServerURL == "" {
+		log.Println("No map server configured, the level map is disabled")
+		return
+	}
+	mapClient = mapdata.NewClient(cfg.MapServerURL, cfg.MapCacheDir)
+}
+
+// currentLevel returns the map of the player's level, or nil until it has
+// been fetched from the map server.
+func currentLevel(state *globals.GameState) *scene.Level {
+	if mapClient == nil || state.Version == 0 || state.MenuShown || state.MapSeed == 0 {
+		return nil
+	}
+	key := currentLevelKey(state)
+	if key != levelShown {
+		if level := requestLevel(key); level != nil {
+			levelShown = key
+			shownLevel = scene.NewLevel(level)
+			size := shownLevel.Image.Bounds().Size()
+			log.Printf("Loaded map of %s (level %d, %dx%d)", level.Name, key.levelNo, size.X, size.Y)
+		}
+	}
+	if key != levelShown {
+		return nil
+	}
+	return shownLevel
+}
+
+// requestLevel returns the level for key once it has been fetched, starting
+// the fetch in the background the first time it is asked for.
+func requestLevel(key levelKey) *mapdata.Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	if levelWanted == key {
+		level := levelFetched
+		levelFetched = nil
+		return level
+	}
+	if failedAt, ok := levelFailed[key]; ok && time.Since(failedAt) < levelRetryInterval {
+		return nil
+	}
+
+	levelWanted = key
+	levelFetched = nil
+	go func() {
+		level, err := mapClient.Level(key.seed, key.difficulty, key.levelNo)
+
+		levelMu.Lock()
+		defer levelMu.Unlock()
+		if err != nil {
+			log.Printf("Failed to load level %d: %v", key.levelNo, err)
+			levelFailed[key] = time.Now()
+			if levelWanted == key {
+				levelWanted = levelKey{}
+			}
+			return
+		}
+		delete(levelFailed, key)
+		if levelWanted == key {
+			levelFetched = level
+		}
+	}()
+	return nil
+}
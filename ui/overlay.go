@@ -3,11 +3,7 @@ package ui
 
 import (
 	"fmt"
-	"image"
-	"image/png"
 	"log"
-	"math"
-	"os"
 	"runtime"
 	"strings"
 	"sync"
@@ -17,7 +13,7 @@ import (
 	"GalyMap/config"
 	"GalyMap/globals"
 	"GalyMap/memory"
-	"GalyMap/types"
+	"GalyMap/scene"
 	"GalyMap/utils"
 
 	"github.com/go-gl/gl/v4.1-core/gl"
@@ -26,12 +22,10 @@ import (
 )
 
 const (
-	width          = 1920
-	height         = 1080
-	GWL_EXSTYLE    = uintptr(0xFFFFFFEC) // -20 in signed 32-bit converted to unsigned
-	globalScale    = float64(4.6)
-	overlayOffsetX = 2
-	overlayOffsetY = -7
+	// Size of the overlay window until it is fitted to the game window
+	width       = 1920
+	height      = 1080
+	GWL_EXSTYLE = uintptr(0xFFFFFFEC) // -20 in signed 32-bit converted to unsigned
 
 	// SetWindowPos flags
 	HWND_TOPMOST   = ^uintptr(0) // (HWND)-1
@@ -41,47 +35,22 @@ const (
 )
 
 var (
-	spriteSheet          *image.RGBA
-	spriteWidth          = 32
-	spriteHeight         = 32
-	sprites              []*Sprite
-	program              uint32
-	vao, vbo, ebo        uint32
-	translationUniform   int32
-	scaleUniform         int32
 	modUser32            = windows.NewLazySystemDLL("user32.dll")
 	procSetWindowLongPtr = modUser32.NewProc("SetWindowLongPtrW")
 	procGetWindowLongPtr = modUser32.NewProc("GetWindowLongPtrW")
 	procSetLayeredWindow = modUser32.NewProc("SetLayeredWindowAttributes")
 	procSetWindowPos     = modUser32.NewProc("SetWindowPos")
 
-	vertexShaderSource = `#version 410
-in vec2 position;
-uniform vec2 translation;
-uniform vec2 scale;
-void main() {
-    gl_Position = vec4(position * scale + translation, 0.0, 1.0);
-}` + "\x00"
-
-	fragmentShaderSource = `#version 410
-out vec4 color;
-void main() {
-    color = vec4(1.0, 0.0, 0.0, 1.0); // Red color
-}` + "\x00"
-
 	// Overlay Control
 	overlayMutex  sync.Mutex
 	overlayClosed bool
 
 	// Synchronization with game data
 	gameDataChan chan struct{}
-)
 
-// Sprite struct holds position and velocity
-type Sprite struct {
-	Position image.Point
-	Velocity image.Point
-}
+	// view maps game coordinates onto the overlay, fitted to the game window
+	view = scene.DefaultView()
+)
 
 func init() {
 	// GLFW requires this to be called on the main thread
@@ -90,6 +59,9 @@ func init() {
 
 // InitializeOverlay sets up the overlay window and OpenGL context
 func InitializeOverlay(processInfo *globals.ProcessInfo, cfg *config.Settings) error {
+	// Work in physical pixels so that the overlay lines up with the game on any monitor
+	setPerMonitorDPIAware()
+
 	// Initialize GLFW
 	if err := glfw.Init(); err != nil {
 		return fmt.Errorf("failed to initialize glfw: %v", err)
@@ -111,7 +83,7 @@ func InitializeOverlay(processInfo *globals.ProcessInfo, cfg *config.Settings) e
 		return fmt.Errorf("failed to create window: %v", err)
 	}
 	window.MakeContextCurrent()
-	window.SetPos(0, 0) // Initial position; will be aligned with game window
+	window.SetPos(0, 0) // Initial position; aligned with the game window below
 
 	// Initialize OpenGL
 	if err := gl.Init(); err != nil {
@@ -121,54 +93,25 @@ func InitializeOverlay(processInfo *globals.ProcessInfo, cfg *config.Settings) e
 	// Apply transparency, click-through styles, and set as topmost
 	setTransparentAndClickThrough(window)
 
-	// Load sprites from sprite sheet
-	loadSprites("sprite_sheet.png")
+	// Cover the game window and fit the view of the display mode to its resolution
+	initDisplay(cfg)
+	gameWindow = newTrackedWindow(processInfo)
+	gameWindow.update(window)
 
-	// Compile and link shaders
-	program, err = newProgram(vertexShaderSource, fragmentShaderSource)
-	if err != nil {
-		return fmt.Errorf("failed to create shader program: %v", err)
-	}
-	gl.UseProgram(program)
-
-	// Retrieve uniform locations
-	translationUniform = gl.GetUniformLocation(program, gl.Str("translation\x00"))
-	scaleUniform = gl.GetUniformLocation(program, gl.Str("scale\x00"))
-
-	// Define vertex data for a rectangle
-	var vertices = []float32{
-		-0.5, -0.5, // Bottom-left
-		0.5, -0.5, // Bottom-right
-		0.5, 0.5, // Top-right
-		-0.5, 0.5, // Top-left
+	// Pack the sprite sheet and shapes into the atlas drawn by the instanced renderer
+	if err := loadAtlas(spriteManifestFile, themeFile, view.UIScale); err != nil {
+		return fmt.Errorf("failed to load sprites: %v", err)
 	}
 
-	var indices = []uint32{
-		0, 1, 2, // First triangle
-		2, 3, 0, // Second triangle
+	// Set up the program drawing the level map layer underneath the sprites
+	if err := initTexturedQuads(); err != nil {
+		return fmt.Errorf("failed to create textured quad program: %v", err)
 	}
+	initLevelMap(cfg)
+	initRoute(cfg)
 
-	// Generate and bind Vertex Array Object
-	gl.GenVertexArrays(1, &vao)
-	gl.BindVertexArray(vao)
-
-	// Generate and bind Vertex Buffer Object
-	gl.GenBuffers(1, &vbo)
-	gl.BindBuffer(gl.ARRAY_BUFFER, vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, 4*len(vertices), gl.Ptr(vertices), gl.STATIC_DRAW)
-
-	// Generate and bind Element Buffer Object
-	gl.GenBuffers(1, &ebo)
-	gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, ebo)
-	gl.BufferData(gl.ELEMENT_ARRAY_BUFFER, 4*len(indices), gl.Ptr(indices), gl.STATIC_DRAW)
-
-	// Enable vertex attributes
-	posAttrib := uint32(gl.GetAttribLocation(program, gl.Str("position\x00")))
-	gl.EnableVertexAttribArray(posAttrib)
-	gl.VertexAttribPointer(posAttrib, 2, gl.FLOAT, false, 0, gl.PtrOffset(0))
-
-	// Unbind VAO (optional)
-	gl.BindVertexArray(0)
+	// Toggle layers, switch display modes and zoom with global hotkeys
+	initHotkeys(cfg)
 
 	// Initialize synchronization channel
 	gameDataChan = make(chan struct{}, 1)
@@ -198,12 +141,26 @@ func RunOverlay() error {
 
 	// Main render loop
 	for !window.ShouldClose() && !overlayClosed {
+		// Follow the game window as it moves and resizes, and run the hotkeys pressed since the last frame
+		gameWindow.update(window)
+		handleHotkeys()
+
 		// Clear the screen with transparent background
 		gl.ClearColor(0, 0, 0, 0)
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
-		// Render all sprites based on current game data
-		renderSprites()
+		// Render the level map, then everything else based on current game
+		// data in a single batch, with the labels and the info panel on top.
+		// Nothing is drawn while the game window is closed or minimized, or
+		// while the display is hidden.
+		frame.Reset()
+		if gameWindow.visible && display.Mode != scene.DisplayHidden {
+			scene.Compose(frame, assets, view, currentScene())
+		}
+		if text, opacity, ok := toaster.Current(time.Now()); ok && gameWindow.visible {
+			scene.ComposeToast(frame, assets, view, text, opacity)
+		}
+		renderer.Render(frame)
 
 		// Swap buffers and poll events
 		window.SwapBuffers()
@@ -212,10 +169,9 @@ func RunOverlay() error {
 	}
 
 	// Cleanup
-	gl.DeleteProgram(program)
-	gl.DeleteVertexArrays(1, &vao)
-	gl.DeleteBuffers(1, &vbo)
-	gl.DeleteBuffers(1, &ebo)
+	closeHotkeys()
+	deleteAtlas()
+	deleteTexturedQuads()
 
 	glfw.Terminate()
 	log.Println("Overlay window closed.")
@@ -255,265 +211,26 @@ func setTransparentAndClickThrough(window *glfw.Window) {
 	}
 }
 
-// loadSprites loads the sprite sheet and creates individual sprites
-func loadSprites(filename string) {
-	file, err := os.Open(filename)
-	if err != nil {
-		log.Fatalf("Failed to open sprite sheet: %v", err)
-	}
-	defer file.Close()
-
-	img, err := png.Decode(file)
-	if err != nil {
-		log.Fatalf("Failed to decode sprite sheet: %v", err)
-	}
-
-	rgba, ok := img.(*image.RGBA)
-	if !ok {
-		rgba = image.NewRGBA(img.Bounds())
-		for y := 0; y < img.Bounds().Dy(); y++ {
-			for x := 0; x < img.Bounds().Dx(); x++ {
-				rgba.Set(x, y, img.At(x, y))
-			}
-		}
-	}
-	spriteSheet = rgba
-
-	// Initialize sprites; this will be replaced with actual game data
-	sprites = []*Sprite{
-		{Position: image.Point{50, 50}, Velocity: image.Point{2, 0}},
-		{Position: image.Point{100, 100}, Velocity: image.Point{0, 2}},
-		{Position: image.Point{150, 150}, Velocity: image.Point{-1, -1}},
-	}
-}
-
-// Update renderSprites to properly handle the player-centered view
-func renderSprites() {
-	// fmt.Printf("Rendering sprites\n")
-	// Get ui
-	uiOpen, err := utils.IsUIOpen()
-	if err != nil {
-		fmt.Printf("Error retrieving UI state: %v\n", err)
-		return
-	}
-
-	if uiOpen {
-		return
-	}
-
-	// Clear existing sprites
-	sprites = make([]*Sprite, 0)
-
-	// Get player position
-	playerPos, err := utils.GetPlayerPosition()
-	if err != nil {
-		fmt.Printf("Error retrieving player position: %v\n", err)
-		return
-	}
-
-	// Get mobs
-	mobs, err := utils.GetMobs()
-	if err != nil {
-		fmt.Printf("Error retrieving mobs: %v\n", err)
-		return
-	}
-
-	// Add mobs relative to player position
-	for _, mob := range mobs {
-		// Only render mobs within visible range
-		if isWithinVisibleRange(mob.Pos.X, mob.Pos.Y, playerPos) {
-			if !mob.IsCorpse && mob.HP > 0 {
-				sprites = append(sprites, &Sprite{
-					Position: image.Point{
-						X: int(mob.Pos.X),
-						Y: int(mob.Pos.Y),
-					},
-					Velocity: image.Point{X: 0, Y: 0},
-				})
-			}
-		}
-	}
-
-	// Get Items
-	items, err := utils.GetItems()
-	if err != nil {
-		fmt.Printf("Error retrieving items: %v\n", err)
-		return
-	}
-
-	FilteredItems := globals.GetFilteredItems()
-	GameMemoryData := globals.GetGameMemoryData()
-	UnfilteredItems := make([]types.Item, 0)
-
-	for _, item := range items {
-		if item.ItemLoc == 3 || item.ItemLoc == 5 {
-			filtered := false
-			//check if any filtered items match this item
-			// fmt.Printf("Checking item: %v\n", item.Name)
-			for _, fp := range FilteredItems {
-				if fp.Match(GameMemoryData["levelNo"].(uint32), item) {
-					filtered = true
-					continue
-				}
-			}
-			if !filtered {
-				UnfilteredItems = append(UnfilteredItems, item)
-				fmt.Printf("Adding item to Unfiltered items: %v\n", item.Name)
-			}
-		}
-	}
-
-	displayadd := make([]types.ItemFootprint, 0)
-	filteredadd := make([]types.ItemFootprint, 0)
-
-	for _, item := range UnfilteredItems {
-		if item.Filter() {
-			fmt.Printf("Item matches a nip filter rule: %v\n", item.Name)
-			// Item matches a nip filter rule add to displayed items
-			displayadd = append(displayadd, types.ItemFootprint{
-				Area:     GameMemoryData["levelNo"].(uint32),
-				Position: image.Point{X: item.ItemX, Y: item.ItemY},
-				Name:     item.Name,
-				Quality:  item.QualityNo,
-			})
-		} else {
-			fmt.Printf("Item does not match a nip filter rule: %v\n", item.Name)
-			// Item does not match a nip filter
-		}
-		filteredadd = append(filteredadd, types.ItemFootprint{
-			Area:     GameMemoryData["levelNo"].(uint32),
-			Position: image.Point{X: item.ItemX, Y: item.ItemY},
-			Name:     item.Name,
-			Quality:  item.QualityNo,
-		})
+// currentScene gathers what the overlay shows for the current game state.
+func currentScene() *scene.Scene {
+	state := globals.CurrentGameState()
+	if state.Version == 0 || state.MenuShown {
+		return &scene.Scene{State: state}
 	}
-
-	DisplayedItems := globals.GetDisplayedItems()
-	DisplayedItems = append(DisplayedItems, displayadd...)
-	updatedDisplayedItems := make([]types.ItemFootprint, 0)
-
-	for _, displayedItem := range DisplayedItems {
-		found := false
-		for _, item := range items {
-			if displayedItem.Match(GameMemoryData["levelNo"].(uint32), item) {
-				found = true
-				break
-			}
-		}
-		if found {
-			updatedDisplayedItems = append(updatedDisplayedItems, displayedItem)
-		} else {
-			// Item no longer exists in game
-			fmt.Printf("Item no longer exists in game: %v\n", displayedItem.Name)
-		}
-	}
-
-	FilteredItems = append(FilteredItems, filteredadd...)
-	globals.SetDisplayedItems(updatedDisplayedItems)
-	globals.SetFilteredItems(FilteredItems)
-
-	// Add Display Items
-	for _, item := range DisplayedItems {
-		sprites = append(sprites, &Sprite{
-			Position: item.Position,
-			Velocity: image.Point{X: 0, Y: 0},
-		})
-	}
-
-	// Add player sprite at the center
-	sprites = append(sprites, &Sprite{
-		Position: image.Point{
-			X: int(playerPos.X),
-			Y: int(playerPos.Y),
-		},
-		Velocity: image.Point{X: 0, Y: 0},
-	})
-
-	// Render all sprites
-	for _, sprite := range sprites {
-		renderSprite(sprite, playerPos)
+	level := currentLevel(state)
+	return &scene.Scene{
+		State:    state,
+		Level:    level,
+		Route:    currentRoute(state, level),
+		Items:    globals.GetDisplayedItems(),
+		Settings: globals.GetSettings(),
 	}
 }
 
-// renderSprite draws a single sprite at its position using shaders
-func renderSprite(sprite *Sprite, playerPos globals.UnitPosition) {
-	gl.UseProgram(program)
-
-	// Convert sprite position from game coordinates to Normalized Device Coordinates (NDC)
-	x_ndc, y_ndc := gameToScreenCoordinatesFloat(float64(sprite.Position.X), float64(sprite.Position.Y), playerPos)
-
-	// Calculate scale in NDC based on sprite size
-	scaleX := float32(spriteWidth) / float32(width)
-	scaleY := float32(spriteHeight) / float32(height)
-
-	// Set uniform values for translation and scale
-	gl.Uniform2f(translationUniform, x_ndc, y_ndc)
-	gl.Uniform2f(scaleUniform, scaleX, scaleY)
-
-	// Bind VAO and draw the rectangle
-	gl.BindVertexArray(vao)
-	gl.DrawElements(gl.TRIANGLES, int32(6), gl.UNSIGNED_INT, nil)
-}
-
-// Helper function to determine if a position is within visible range
-func isWithinVisibleRange(x, y float64, playerPos globals.UnitPosition) bool {
-	// Calculate distance from player
-	dx := x - playerPos.X
-	dy := y - playerPos.Y
-
-	// Define visible range based on screen size and scale
-	maxRange := float64(width) / (2 * globalScale) // Adjust this calculation based on your needs
-
-	// Check if position is within visible range
-	distanceSquared := dx*dx + dy*dy
-	return distanceSquared <= maxRange*maxRange
-}
-
-// Transform game coordinates into screen coordinates while keeping the player centered
-func gameToScreenCoordinates(gameX, gameY float64, playerPos globals.UnitPosition) (int, int) {
-	// Calculate the relative position from player
-	relativeX := gameX - playerPos.X
-	relativeY := gameY - playerPos.Y
-
-	// Define constants
-	const (
-		// Base scale factor (adjust this to change the overall zoom level)
-		baseScale = globalScale
-		// Isometric rotation angle (45 degrees)
-		angleRadians = math.Pi / 4
-		// Y-axis compression factor for isometric view
-		yCompression = 0.5
-	)
-
-	// Scale can be adjusted dynamically if needed
-	scale := baseScale
-
-	// Apply isometric rotation
-	rotatedX := relativeX*math.Cos(angleRadians) - relativeY*math.Sin(angleRadians)
-	rotatedY := relativeX*math.Sin(angleRadians) + relativeY*math.Cos(angleRadians)
-
-	// Apply scaling and Y compression
-	scaledX := rotatedX * scale
-	scaledY := rotatedY * scale * yCompression
-
-	// Convert to screen coordinates (player is always at center)
-	screenX := float64(width/2) + scaledX + float64(overlayOffsetX)
-	screenY := float64(height/2) + scaledY + float64(overlayOffsetY)
-
-	// Bounds checking to ensure coordinates stay within screen
-	screenX = math.Max(0, math.Min(float64(width), screenX))
-	screenY = math.Max(0, math.Min(float64(height), screenY))
-
-	return int(screenX), int(screenY)
-}
-
-// Convert game coordinates to NDC (Normalized Device Coordinates)
-func gameToScreenCoordinatesFloat(gameX, gameY float64, playerPos globals.UnitPosition) (float32, float32) {
-	screenX, screenY := gameToScreenCoordinates(gameX, gameY, playerPos)
-
-	// Convert to NDC space (-1 to 1)
-	ndcX := (float32(screenX)/float32(width))*2.0 - 1.0
-	ndcY := -((float32(screenY)/float32(height))*2.0 - 1.0)
+// screenToNDC converts overlay pixel coordinates to NDC space (-1 to 1)
+func screenToNDC(screenX, screenY float64) (float32, float32) {
+	ndcX := (float32(screenX)/float32(view.Width))*2.0 - 1.0
+	ndcY := -((float32(screenY)/float32(view.Height))*2.0 - 1.0)
 
 	return ndcX, ndcY
 }
@@ -577,27 +294,83 @@ func newProgram(vertexSrc, fragmentSrc string) (uint32, error) {
 	return prog, nil
 }
 
-// ReadGameMemoryRoutine continuously reads game memory and updates globals
-func ReadGameMemoryRoutine(d2r *utils.ClassMemory, cfg *config.Settings) {
+// ReadGameMemoryRoutine continuously reads game memory and updates globals.
+// The supervisor keeps it attached to D2R across restarts of the game.
+func ReadGameMemoryRoutine(supervisor *memory.Supervisor, cfg *config.Settings) {
 	ticker := time.NewTicker(50 * time.Millisecond)
 	defer ticker.Stop()
 
-	// Check if player is in-game and log the result
-	unitTableOffset, exists := globals.GetOffset("unitTable")
-	if !exists {
-		log.Fatalf("main.go: Failed to retrieve 'unitTable' offset from globals.")
-	}
-	inGame, err := memory.IsInGame(d2r, unitTableOffset)
-	if err != nil {
-		log.Fatalf("Failed to check if player is in-game: %v", err)
+	snapshotSaved := false
+
+	// Serve each tick's reads from a page cache so that every page is read from D2R at most once per tick
+	var reader *utils.CachedReader
+	statsTicks := 0
+
+	// Log the map seeds rustdecrypt.dll derives differently
+	if cfg.VerifyMapSeeds {
+		memory.VerifyMapSeeds()
 	}
 
+	// Back off while out of game or while reads fail, and recover by rescanning
+	recovery := &memory.Recovery{}
+
+	// Track joining and leaving games so that nothing carries over between them
+	session := supervisor.Session()
+	session.Subscribe(func(event memory.SessionEvent) {
+		switch event.Type {
+		case memory.GameJoined:
+			log.Printf("Joined game as %s (difficulty %d, area %d)", event.Game.PlayerName, event.Game.Difficulty, event.Game.LevelNo)
+		case memory.GameLeft:
+			log.Printf("Left game after %v", event.Duration.Round(time.Second))
+		}
+	})
+
 	for {
 		select {
 		case <-ticker.C:
 			globals.IncrementTicktock()
-			if inGame {
-				memory.ReadGameMemory(d2r, globals.Settings)
+
+			d2r, changed := supervisor.Poll()
+			if changed || reader == nil {
+				// Start over with the new process, or with nothing while D2R isn't running
+				reader = nil
+				recovery = &memory.Recovery{}
+				if d2r != nil {
+					reader = utils.NewCachedReader(d2r)
+				}
+			}
+			if reader == nil || recovery.Wait() {
+				continue
+			}
+
+			reader.Reset()
+			err := memory.ReadGameMemory(reader, globals.GetSettings())
+			session.Update(reader, err)
+			if err == nil {
+				// Run the new items on the ground through the NIP rules for the renderer
+				displayed, filtered := scene.DisplayedItems(globals.CurrentGameState(), globals.GetDisplayedItems(), globals.GetFilteredItems())
+				globals.SetDisplayedItems(displayed)
+				globals.SetFilteredItems(filtered)
+			}
+			recovery.Handle(d2r, err)
+
+			// Log the per-tick read counters every 5 seconds in debug mode
+			statsTicks++
+			if statsTicks == 100 {
+				stats := reader.TakeStats()
+				if cfg.Debug {
+					n := uint64(statsTicks)
+					log.Printf("Reads per tick: %d requested, %d served from cache, %d from D2R (%d KiB, %v)",
+						stats.Requests/n, stats.Hits/n, stats.SourceReads/n, stats.SourceBytes/n/1024, stats.SourceTime/time.Duration(n))
+				}
+				statsTicks = 0
+			}
+
+			// Capture a memory snapshot of one tick for offline analysis
+			if err == nil && cfg.SnapshotFile != "" && !snapshotSaved {
+				err := memory.CaptureSnapshot(d2r, globals.GetSettings(), cfg.SnapshotFile)
+				utils.IfError(err, "Failed to capture memory snapshot")
+				snapshotSaved = true
 			}
 		}
 	}
@@ -0,0 +1,87 @@
+// ui/route.go
+package ui
+
+import (
+	"errors"
+	"image"
+	"log"
+	"strings"
+	"sync"
+
+	"GalyMap/config"
+	"GalyMap/globals"
+	"GalyMap/pathfinding"
+	"GalyMap/scene"
+)
+
+const (
+	// replanDistance is how far the player moves, in game units, before the route is planned again
+	replanDistance = 5
+	// maxRouteNodes bounds a single search so that unreachable targets give up quickly
+	maxRouteNodes = 400000
+)
+
+var (
+	routeTarget  string
+	routeOptions pathfinding.Options
+
+	// Route planned in the background, guarded by routeMu
+	routeMu       sync.Mutex
+	routePlanning bool
+	routeLevel    levelKey
+	routeFrom     image.Point
+	routeGoal     image.Point
+	routePoints   []image.Point // in game coordinates
+)
+
+// initRoute reads the route settings.
+func initRoute(cfg *config.Settings) {
+	routeTarget = strings.ToLower(strings.TrimSpace(cfg.PathTarget))
+	routeOptions = pathfinding.Options{TeleportRange: cfg.TeleportRange, MaxNodes: maxRouteNodes}
+}
+
+// currentRoute returns the route from the player to the configured target in
+// level, planning it again in the background whenever the player or the
+// target moves. It is empty until the first plan is ready.
+func currentRoute(state *globals.GameState, level *scene.Level) []image.Point {
+	if routeTarget == "" || level == nil {
+		return nil
+	}
+	key := currentLevelKey(state)
+	target, ok := scene.RouteTarget(state, scene.Markers(state, level), routeTarget)
+	if !ok {
+		return nil
+	}
+	start := image.Point{X: int(state.Pos.X), Y: int(state.Pos.Y)}
+	goal := image.Point{X: target.X, Y: target.Y}
+
+	routeMu.Lock()
+	moved := start.Sub(routeFrom)
+	stale := routeLevel != key || routeGoal != goal || moved.X*moved.X+moved.Y*moved.Y >= replanDistance*replanDistance
+	if stale && !routePlanning {
+		routePlanning = true
+		go planRoute(level, key, start, goal)
+	}
+	var points []image.Point
+	if routeLevel == key && routeGoal == goal {
+		points = routePoints
+	}
+	routeMu.Unlock()
+	return points
+}
+
+// planRoute searches for a route over the grid of level.
+func planRoute(level *scene.Level, key levelKey, start, goal image.Point) {
+	points, err := scene.PlanRoute(level, start, goal, routeOptions)
+	if err != nil && !errors.Is(err, pathfinding.ErrNoPath) {
+		log.Printf("Route planning failed: %v", err)
+	}
+
+	routeMu.Lock()
+	defer routeMu.Unlock()
+	routePlanning = false
+	routeLevel = key
+	routeFrom = start
+	routeGoal = goal
+	routePoints = points
+}
@@ -0,0 +1,256 @@
+// ui/sprites.go
+package ui
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"GalyMap/render"
+	"GalyMap/scene"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+const (
+	// spriteManifestFile maps entity kinds to cells of the sprite sheet
+	spriteManifestFile = "config/sprites.yaml"
+	// themeFile sets the color, shape and size of each entity kind
+	themeFile = "config/theme.yaml"
+	// floatsPerInstance is the instance layout: center x, y, size x, y,
+	// rotation, then the color and the atlas cell stored as raw 32-bit values
+	floatsPerInstance = 7
+)
+
+var (
+	assets       *scene.Assets
+	assetsScale  float64 // UIScale the label font of assets is rasterized for
+	atlasTexture uint32
+	frame        *render.Frame
+	renderer     *glRenderer
+
+	instanceShaderVertex = `#version 410
+layout(location = 0) in vec2 corner;
+layout(location = 1) in vec2 center;
+layout(location = 2) in vec2 size;
+layout(location = 3) in float rotation;
+layout(location = 4) in vec4 tint;
+layout(location = 5) in uint cell;
+uniform samplerBuffer cells;
+uniform vec2 viewport;
+out vec2 uv;
+out vec4 instanceColor;
+void main() {
+    vec2 offset = corner * size;
+    float s = sin(rotation);
+    float c = cos(rotation);
+    vec2 pixel = center + vec2(offset.x * c - offset.y * s, offset.x * s + offset.y * c);
+    gl_Position = vec4(pixel.x / viewport.x * 2.0 - 1.0, 1.0 - pixel.y / viewport.y * 2.0, 0.0, 1.0);
+    vec4 rect = texelFetch(cells, int(cell));
+    uv = mix(rect.xy, rect.zw, corner + 0.5);
+    instanceColor = tint;
+}` + "\x00"
+
+	instanceShaderFragment = `#version 410
+in vec2 uv;
+in vec4 instanceColor;
+uniform sampler2D atlas;
+out vec4 color;
+void main() {
+    color = texture(atlas, uv) * instanceColor;
+}` + "\x00"
+)
+
+// glRenderer draws the layers of a render.Frame as textured quads, then its
+// instances with a single instanced draw call. The atlas cells are looked up
+// in the shader from a buffer texture of their texture coordinates, so an
+// instance only carries the cell index.
+type glRenderer struct {
+	program         uint32
+	vao             uint32
+	cornerVBO       uint32
+	instanceVBO     uint32
+	cellBuffer      uint32
+	cellTexture     uint32
+	atlasUniform    int32
+	cellsUniform    int32
+	viewportUniform int32
+	instances       []float32
+	layerTextures   map[*image.NRGBA]uint32 // uploaded once per layer image
+}
+
+// loadAtlas loads the sprite sheet, the theme and the label font for views of
+// uiScale into the atlas, uploads it and sets up the renderer, replacing the
+// previous ones
+func loadAtlas(manifestFile, themeFile string, uiScale float64) error {
+	loaded, err := scene.LoadAssets(manifestFile, themeFile, uiScale)
+	if err != nil {
+		return err
+	}
+	deleteAtlas()
+	assets, assetsScale = loaded, uiScale
+	// Sheet sprites are drawn much smaller than they are, so sample them smoothly
+	uploadTextureFiltered(&atlasTexture, assets.Atlas.Image, gl.LINEAR)
+
+	renderer, err = newGLRenderer(assets.Atlas)
+	if err != nil {
+		return err
+	}
+	frame = render.NewFrame(assets.Atlas)
+	return nil
+}
+
+func newGLRenderer(atlas *render.Atlas) (*glRenderer, error) {
+	r := &glRenderer{layerTextures: make(map[*image.NRGBA]uint32)}
+	var err error
+	r.program, err = newProgram(instanceShaderVertex, instanceShaderFragment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instance program: %v", err)
+	}
+	r.atlasUniform = gl.GetUniformLocation(r.program, gl.Str("atlas\x00"))
+	r.cellsUniform = gl.GetUniformLocation(r.program, gl.Str("cells\x00"))
+	r.viewportUniform = gl.GetUniformLocation(r.program, gl.Str("viewport\x00"))
+
+	// Texture coordinates of every atlas cell
+	cells := make([]float32, 0, 4*atlas.Len())
+	for i := 0; i < atlas.Len(); i++ {
+		uv := atlas.UV(i)
+		cells = append(cells, uv[:]...)
+	}
+	gl.GenBuffers(1, &r.cellBuffer)
+	gl.BindBuffer(gl.TEXTURE_BUFFER, r.cellBuffer)
+	gl.BufferData(gl.TEXTURE_BUFFER, 4*len(cells), gl.Ptr(cells), gl.STATIC_DRAW)
+	gl.GenTextures(1, &r.cellTexture)
+	gl.BindTexture(gl.TEXTURE_BUFFER, r.cellTexture)
+	gl.TexBuffer(gl.TEXTURE_BUFFER, gl.RGBA32F, r.cellBuffer)
+
+	// A unit quad shared by all instances
+	corners := []float32{-0.5, -0.5, 0.5, -0.5, 0.5, 0.5, -0.5, 0.5}
+
+	gl.GenVertexArrays(1, &r.vao)
+	gl.BindVertexArray(r.vao)
+
+	gl.GenBuffers(1, &r.cornerVBO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.cornerVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(corners), gl.Ptr(corners), gl.STATIC_DRAW)
+	gl.EnableVertexAttribArray(0)
+	gl.VertexAttribPointer(0, 2, gl.FLOAT, false, 0, gl.PtrOffset(0))
+
+	// One instance per quad, refilled every frame
+	gl.GenBuffers(1, &r.instanceVBO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instanceVBO)
+	stride := int32(4 * floatsPerInstance)
+	for _, attribute := range []struct {
+		location   uint32
+		components int32
+		offset     int
+	}{
+		{1, 2, 0}, // center
+		{2, 2, 2}, // size
+		{3, 1, 4}, // rotation
+	} {
+		gl.EnableVertexAttribArray(attribute.location)
+		gl.VertexAttribPointer(attribute.location, attribute.components, gl.FLOAT, false, stride, gl.PtrOffset(4*attribute.offset))
+		gl.VertexAttribDivisor(attribute.location, 1)
+	}
+	gl.EnableVertexAttribArray(4) // tint, four normalized bytes
+	gl.VertexAttribPointer(4, 4, gl.UNSIGNED_BYTE, true, stride, gl.PtrOffset(4*5))
+	gl.VertexAttribDivisor(4, 1)
+	gl.EnableVertexAttribArray(5) // cell index
+	gl.VertexAttribIPointer(5, 1, gl.UNSIGNED_INT, stride, gl.PtrOffset(4*6))
+	gl.VertexAttribDivisor(5, 1)
+
+	gl.BindVertexArray(0)
+	return r, nil
+}
+
+// Render draws the layers of frame, then every instance of frame in one call
+func (r *glRenderer) Render(frame *render.Frame) {
+	r.renderLayers(frame.Layers)
+	if len(frame.Instances) == 0 {
+		return
+	}
+	r.instances = r.instances[:0]
+	for _, instance := range frame.Instances {
+		c := instance.Color
+		r.instances = append(r.instances,
+			instance.X, instance.Y,
+			instance.Width, instance.Height,
+			instance.Rotation,
+			math.Float32frombits(uint32(c.R)|uint32(c.G)<<8|uint32(c.B)<<16|uint32(c.A)<<24),
+			math.Float32frombits(uint32(instance.Cell)))
+	}
+
+	gl.UseProgram(r.program)
+	gl.Uniform2f(r.viewportUniform, float32(view.Width), float32(view.Height))
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, atlasTexture)
+	gl.Uniform1i(r.atlasUniform, 0)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_BUFFER, r.cellTexture)
+	gl.Uniform1i(r.cellsUniform, 1)
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	gl.BindVertexArray(r.vao)
+	gl.BindBuffer(gl.ARRAY_BUFFER, r.instanceVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*len(r.instances), gl.Ptr(r.instances), gl.STREAM_DRAW)
+	gl.DrawArraysInstanced(gl.TRIANGLE_FAN, 0, 4, int32(len(frame.Instances)))
+	gl.BindVertexArray(0)
+}
+
+// renderLayers draws layers as textured quads, cut to their clip rectangle
+// with the scissor test, uploading the images it hasn't seen yet and
+// releasing the textures of those no longer drawn.
+func (r *glRenderer) renderLayers(layers []render.Layer) {
+	drawn := make(map[*image.NRGBA]bool, len(layers))
+	for _, layer := range layers {
+		texture := r.layerTextures[layer.Image]
+		if texture == 0 {
+			uploadTexture(&texture, layer.Image)
+			r.layerTextures[layer.Image] = texture
+		}
+		var corners [4][2]float32
+		for i, corner := range layer.Corners {
+			corners[i][0], corners[i][1] = screenToNDC(float64(corner[0]), float64(corner[1]))
+		}
+		if !layer.Clip.Empty() {
+			// The scissor box is in window pixels from the bottom left corner
+			gl.Enable(gl.SCISSOR_TEST)
+			gl.Scissor(int32(layer.Clip.Min.X), int32(view.Height)-int32(layer.Clip.Max.Y), int32(layer.Clip.Dx()), int32(layer.Clip.Dy()))
+		}
+		drawTexturedQuad(texture, corners)
+		gl.Disable(gl.SCISSOR_TEST)
+		drawn[layer.Image] = true
+	}
+	for img, texture := range r.layerTextures {
+		if !drawn[img] {
+			gl.DeleteTextures(1, &texture)
+			delete(r.layerTextures, img)
+		}
+	}
+}
+
+// Delete releases the renderer's GL objects
+func (r *glRenderer) Delete() {
+	gl.DeleteProgram(r.program)
+	gl.DeleteVertexArrays(1, &r.vao)
+	gl.DeleteBuffers(1, &r.cornerVBO)
+	gl.DeleteBuffers(1, &r.instanceVBO)
+	gl.DeleteTextures(1, &r.cellTexture)
+	gl.DeleteBuffers(1, &r.cellBuffer)
+	for _, texture := range r.layerTextures {
+		gl.DeleteTextures(1, &texture)
+	}
+}
+
+// deleteAtlas releases the atlas and the renderer
+func deleteAtlas() {
+	if atlasTexture != 0 {
+		gl.DeleteTextures(1, &atlasTexture)
+		atlasTexture = 0
+	}
+	if renderer != nil {
+		renderer.Delete()
+		renderer = nil
+	}
+}
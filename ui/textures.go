@@ -0,0 +1,107 @@
+// ui/textures.go
+package ui
+
+import (
+	"image"
+
+	"github.com/go-gl/gl/v4.1-core/gl"
+)
+
+var (
+	quadProgram        uint32
+	quadVAO, quadVBO   uint32
+	quadTextureUniform int32
+
+	quadShaderVertex = `#version 410
+in vec2 position;
+in vec2 texCoord;
+out vec2 uv;
+void main() {
+    gl_Position = vec4(position, 0.0, 1.0);
+    uv = texCoord;
+}` + "\x00"
+
+	quadShaderFragment = `#version 410
+in vec2 uv;
+uniform sampler2D quadTexture;
+out vec4 color;
+void main() {
+    color = texture(quadTexture, uv);
+}` + "\x00"
+)
+
+// initTexturedQuads sets up the program used to draw textures onto arbitrary quads.
+func initTexturedQuads() error {
+	var err error
+	quadProgram, err = newProgram(quadShaderVertex, quadShaderFragment)
+	if err != nil {
+		return err
+	}
+	quadTextureUniform = gl.GetUniformLocation(quadProgram, gl.Str("quadTexture\x00"))
+
+	// Four corners of x, y, u, v, rewritten for every quad
+	gl.GenVertexArrays(1, &quadVAO)
+	gl.BindVertexArray(quadVAO)
+	gl.GenBuffers(1, &quadVBO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, quadVBO)
+	gl.BufferData(gl.ARRAY_BUFFER, 4*4*4, nil, gl.DYNAMIC_DRAW)
+
+	posAttrib := uint32(gl.GetAttribLocation(quadProgram, gl.Str("position\x00")))
+	gl.EnableVertexAttribArray(posAttrib)
+	gl.VertexAttribPointer(posAttrib, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(0))
+	uvAttrib := uint32(gl.GetAttribLocation(quadProgram, gl.Str("texCoord\x00")))
+	gl.EnableVertexAttribArray(uvAttrib)
+	gl.VertexAttribPointer(uvAttrib, 2, gl.FLOAT, false, 4*4, gl.PtrOffset(2*4))
+
+	gl.BindVertexArray(0)
+	return nil
+}
+
+// drawTexturedQuad draws texture onto the quad with the given corners in NDC,
+// in the order top-left, top-right, bottom-right, bottom-left of the texture.
+func drawTexturedQuad(texture uint32, corners [4][2]float32) {
+	uvs := [4][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	vertices := make([]float32, 0, 16)
+	for i, corner := range corners {
+		vertices = append(vertices, corner[0], corner[1], uvs[i][0], uvs[i][1])
+	}
+
+	gl.UseProgram(quadProgram)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.Uniform1i(quadTextureUniform, 0)
+
+	gl.BindVertexArray(quadVAO)
+	gl.BindBuffer(gl.ARRAY_BUFFER, quadVBO)
+	gl.BufferSubData(gl.ARRAY_BUFFER, 0, 4*len(vertices), gl.Ptr(vertices))
+	gl.DrawArrays(gl.TRIANGLE_FAN, 0, 4)
+	gl.BindVertexArray(0)
+}
+
+// uploadTexture uploads img to texture, creating the texture if it is 0.
+// Colors are not premultiplied, matching the overlay's blend function.
+func uploadTexture(texture *uint32, img *image.NRGBA) {
+	uploadTextureFiltered(texture, img, gl.NEAREST)
+}
+
+// uploadTextureFiltered uploads img to texture sampled with filter.
+func uploadTextureFiltered(texture *uint32, img *image.NRGBA, filter int32) {
+	if *texture == 0 {
+		gl.GenTextures(1, texture)
+	}
+	bounds := img.Bounds()
+	gl.BindTexture(gl.TEXTURE_2D, *texture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, filter)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(bounds.Dx()), int32(bounds.Dy()), 0, gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(img.Pix))
+}
+
+// deleteTexturedQuads releases the GL objects of the textured quad program.
+func deleteTexturedQuads() {
+	gl.DeleteProgram(quadProgram)
+	gl.DeleteVertexArrays(1, &quadVAO)
+	gl.DeleteBuffers(1, &quadVBO)
+}
@@ -0,0 +1,198 @@
+// utils/decode.go
+
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// Structs decoded by Decode describe a memory layout with an offset tag on
+// each field that should be read, for example:
+//
+//	type Path struct {
+//		XOffset uint16 `offset:"0x00"`
+//		X       uint16 `offset:"0x02"`
+//	}
+//
+// Supported field types are the fixed-size integers, floats, bool and byte
+// arrays. Untagged fields are left alone.
+
+type structField struct {
+	index  int
+	offset int
+	size   int
+	kind   reflect.Kind
+}
+
+type structLayout struct {
+	fields []structField
+	size   int
+}
+
+var structLayouts sync.Map // reflect.Type -> *structLayout
+
+// layoutOf returns the cached layout of a tagged struct type.
+func layoutOf(t reflect.Type) (*structLayout, error) {
+	if cached, ok := structLayouts.Load(t); ok {
+		return cached.(*structLayout), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot decode into %v", t)
+	}
+
+	layout := &structLayout{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("offset")
+		if !ok {
+			continue
+		}
+		offset, err := strconv.ParseUint(tag, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%v.%s: invalid offset %q", t, field.Name, tag)
+		}
+		kind := field.Type.Kind()
+		var size int
+		switch kind {
+		case reflect.Bool, reflect.Uint8, reflect.Int8:
+			size = 1
+		case reflect.Uint16, reflect.Int16:
+			size = 2
+		case reflect.Uint32, reflect.Int32, reflect.Float32:
+			size = 4
+		case reflect.Uint64, reflect.Int64, reflect.Float64, reflect.Uintptr:
+			size = 8
+		case reflect.Array:
+			if field.Type.Elem().Kind() != reflect.Uint8 {
+				return nil, fmt.Errorf("%v.%s: only byte arrays are supported", t, field.Name)
+			}
+			size = field.Type.Len()
+		default:
+			return nil, fmt.Errorf("%v.%s: unsupported type %v", t, field.Name, field.Type)
+		}
+		layout.fields = append(layout.fields, structField{index: i, offset: int(offset), size: size, kind: kind})
+		if end := int(offset) + size; end > layout.size {
+			layout.size = end
+		}
+	}
+
+	structLayouts.Store(t, layout)
+	return layout, nil
+}
+
+// SizeOf returns the number of bytes needed to decode a value of v's struct type.
+func SizeOf(v interface{}) int {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	layout, err := layoutOf(t)
+	if err != nil {
+		panic(err)
+	}
+	return layout.size
+}
+
+// Decode fills the tagged fields of the struct pointed to by v from data.
+func Decode(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("decode needs a non-nil struct pointer")
+	}
+	rv = rv.Elem()
+	layout, err := layoutOf(rv.Type())
+	if err != nil {
+		return err
+	}
+	if len(data) < layout.size {
+		return fmt.Errorf("decoding %v needs %d bytes, got %d", rv.Type(), layout.size, len(data))
+	}
+
+	for _, f := range layout.fields {
+		field := rv.Field(f.index)
+		raw := data[f.offset : f.offset+f.size]
+		switch f.kind {
+		case reflect.Bool:
+			field.SetBool(raw[0] != 0)
+		case reflect.Uint8:
+			field.SetUint(uint64(raw[0]))
+		case reflect.Uint16:
+			field.SetUint(uint64(binary.LittleEndian.Uint16(raw)))
+		case reflect.Uint32:
+			field.SetUint(uint64(binary.LittleEndian.Uint32(raw)))
+		case reflect.Uint64, reflect.Uintptr:
+			field.SetUint(binary.LittleEndian.Uint64(raw))
+		case reflect.Int8:
+			field.SetInt(int64(int8(raw[0])))
+		case reflect.Int16:
+			field.SetInt(int64(int16(binary.LittleEndian.Uint16(raw))))
+		case reflect.Int32:
+			field.SetInt(int64(int32(binary.LittleEndian.Uint32(raw))))
+		case reflect.Int64:
+			field.SetInt(int64(binary.LittleEndian.Uint64(raw)))
+		case reflect.Float32:
+			field.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(raw))))
+		case reflect.Float64:
+			field.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(raw)))
+		case reflect.Array:
+			reflect.Copy(field, reflect.ValueOf(raw))
+		}
+	}
+	return nil
+}
+
+// ReadStruct reads a T from the process at address and decodes it.
+func ReadStruct[T any](d2r ProcessReader, address uintptr) (T, error) {
+	var v T
+	if err := CheckPointer(address); err != nil {
+		return v, fmt.Errorf("reading %T: %w", v, err)
+	}
+	data, err := d2r.ReadRaw(address, uint32(SizeOf(&v)))
+	if err != nil {
+		return v, fmt.Errorf("reading %T: %w", v, readFault(address, err))
+	}
+	if len(data) < SizeOf(&v) {
+		return v, fmt.Errorf("reading %T: %w", v, readFault(address, errors.New("short read")))
+	}
+	if err := Decode(data, &v); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// MaxStructs is the largest count ReadStructs accepts. Counts are read from game
+// memory, where a freed or half-built structure can hold any value.
+const MaxStructs = 512
+
+// ReadStructs reads count consecutive T values of stride bytes each starting at address.
+// Counts above MaxStructs are rejected rather than read.
+func ReadStructs[T any](d2r ProcessReader, address uintptr, count int, stride int) ([]T, error) {
+	var zero T
+	if count == 0 {
+		return nil, nil
+	}
+	if count < 0 || count > MaxStructs {
+		return nil, fmt.Errorf("reading %T array: count %d is outside 0..%d", zero, count, MaxStructs)
+	}
+	if err := CheckPointer(address); err != nil {
+		return nil, fmt.Errorf("reading %T array: %w", zero, err)
+	}
+	size := SizeOf(&zero)
+	data, err := d2r.ReadRaw(address, uint32((count-1)*stride+size))
+	if err != nil {
+		return nil, fmt.Errorf("reading %d x %T: %w", count, zero, readFault(address, err))
+	}
+	values := make([]T, count)
+	for i := range values {
+		if err := Decode(data[i*stride:], &values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,163 @@
+// utils/decode_test.go
+
+package utils
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+type testStat struct {
+	Id    uint16 `offset:"0x02"`
+	Value uint32 `offset:"0x04"`
+}
+
+// testMixed has a field of every supported kind, out of offset order and with gaps
+type testMixed struct {
+	Pointer  uint64  `offset:"0x18"`
+	Flag     uint8   `offset:"0x00"`
+	Signed8  int8    `offset:"0x01"`
+	Short    uint16  `offset:"0x02"`
+	Signed16 int16   `offset:"0x04"`
+	Word     uint32  `offset:"0x08"`
+	Signed32 int32   `offset:"0x0C"`
+	Float    float32 `offset:"0x10"`
+	Alive    bool    `offset:"0x14"`
+	Address  uintptr `offset:"0x20"`
+	Signed64 int64   `offset:"0x28"`
+	Double   float64 `offset:"0x30"`
+	Name     [6]byte `offset:"0x38"`
+	Skipped  int     // untagged fields are left alone
+}
+
+// testOuter and testInner are a structure pointing at another, as units point at their paths
+type testOuter struct {
+	Id    uint32 `offset:"0x04"`
+	Inner uint64 `offset:"0x10"`
+}
+
+type testInner struct {
+	X uint16 `offset:"0x02"`
+	Y uint16 `offset:"0x06"`
+}
+
+func TestDecode(t *testing.T) {
+	data := make([]byte, 0x40)
+	data[0x00] = 0xAB
+	data[0x01] = 0xFE // -2
+	binary.LittleEndian.PutUint16(data[0x02:], 0xBEEF)
+	binary.LittleEndian.PutUint16(data[0x04:], 0x8000) // -32768
+	binary.LittleEndian.PutUint32(data[0x08:], 0xDEADBEEF)
+	binary.LittleEndian.PutUint32(data[0x0C:], 0xFFFFFFFF) // -1
+	binary.LittleEndian.PutUint32(data[0x10:], math.Float32bits(1.5))
+	data[0x14] = 2 // any nonzero byte is true
+	binary.LittleEndian.PutUint64(data[0x18:], 0x7FF6_1234_5678)
+	binary.LittleEndian.PutUint64(data[0x20:], 0x1_4000_0000)
+	binary.LittleEndian.PutUint64(data[0x28:], 0xFFFFFFFFFFFFFFF6) // -10
+	binary.LittleEndian.PutUint64(data[0x30:], math.Float64bits(-0.25))
+	copy(data[0x38:], "Andy\x00\x00")
+
+	got := testMixed{Skipped: 7}
+	if err := Decode(data, &got); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	want := testMixed{
+		Flag: 0xAB, Signed8: -2, Short: 0xBEEF, Signed16: -32768, Word: 0xDEADBEEF, Signed32: -1,
+		Float: 1.5, Alive: true, Pointer: 0x7FF6_1234_5678, Address: 0x1_4000_0000, Signed64: -10,
+		Double: -0.25, Name: [6]byte{'A', 'n', 'd', 'y'}, Skipped: 7,
+	}
+	if got != want {
+		t.Errorf("Decode =\n%+v\nwant\n%+v", got, want)
+	}
+	if size := SizeOf(testMixed{}); size != 0x3E {
+		t.Errorf("SizeOf = 0x%X, want 0x3E, the end of the last field", size)
+	}
+
+	// Decoding needs every byte up to the end of the last field
+	if err := Decode(data[:0x3D], &got); err == nil {
+		t.Errorf("Decode of a short buffer succeeded")
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	data := make([]byte, 0x40)
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"not a pointer", testStat{}, "non-nil struct pointer"},
+		{"nil pointer", (*testStat)(nil), "non-nil struct pointer"},
+		{"not a struct", new(uint32), "cannot decode"},
+		{"bad offset", &struct {
+			A uint32 `offset:"ten"`
+		}{}, "invalid offset"},
+		{"unsupported type", &struct {
+			A string `offset:"0x00"`
+		}{}, "unsupported type"},
+		{"array of words", &struct {
+			A [2]uint16 `offset:"0x00"`
+		}{}, "only byte arrays"},
+	}
+	for _, tt := range tests {
+		err := Decode(data, tt.v)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Decode = %v, want an error about %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestReadStructPointers(t *testing.T) {
+	process := NewFakeProcess(FakeModuleSize + 0x1000)
+	outer, inner := FakeBase+FakeModuleSize, FakeBase+FakeModuleSize+0x800
+	process.PutUint32(outer+0x04, 42)
+	process.PutUint64(outer+0x10, uint64(inner))
+	process.PutUint16(inner+0x02, 5100)
+	process.PutUint16(inner+0x06, 5200)
+
+	o, err := ReadStruct[testOuter](process, outer)
+	if err != nil || o.Id != 42 || o.Inner != uint64(inner) {
+		t.Fatalf("ReadStruct[testOuter] = %+v, %v", o, err)
+	}
+	i, err := ReadStruct[testInner](process, uintptr(o.Inner))
+	if err != nil || i != (testInner{5100, 5200}) {
+		t.Errorf("ReadStruct[testInner] through the pointer = %+v, %v", i, err)
+	}
+
+	// Null and small pointers are rejected without a read, unmapped ones fail the read
+	process.Reads = 0
+	for _, address := range []uintptr{0, 0x10} {
+		if _, err := ReadStruct[testInner](process, address); err == nil {
+			t.Errorf("ReadStruct at 0x%X succeeded", address)
+		}
+	}
+	if process.Reads != 0 {
+		t.Errorf("invalid pointers made %d reads", process.Reads)
+	}
+	if _, err := ReadStruct[testInner](process, FakeBase+0x10000); err == nil {
+		t.Errorf("ReadStruct of unmapped memory succeeded")
+	}
+}
+
+func TestReadStructsCount(t *testing.T) {
+	process := NewFakeProcess(FakeModuleSize + 0x1000)
+	array := FakeBase + FakeModuleSize
+	process.Put(array, []byte{0, 0, 12, 0, 90, 0, 0, 0, 0, 0, 13, 0, 0x10, 0x27, 0, 0})
+
+	stats, err := ReadStructs[testStat](process, array, 2, 8)
+	if err != nil || len(stats) != 2 || stats[0] != (testStat{12, 90}) || stats[1] != (testStat{13, 10000}) {
+		t.Errorf("ReadStructs = %+v, %v", stats, err)
+	}
+
+	process.Reads = 0
+	for _, count := range []int{-1, MaxStructs + 1, 0x7FFFFFFF} {
+		if _, err := ReadStructs[testStat](process, array, count, 8); err == nil {
+			t.Errorf("ReadStructs accepted a count of %d", count)
+		}
+	}
+	if process.Reads != 0 {
+		t.Errorf("rejected counts made %d reads", process.Reads)
+	}
+}
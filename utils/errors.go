@@ -0,0 +1,33 @@
+// utils/errors.go
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrReadFault means the target process memory could not be read
+	ErrReadFault = errors.New("read fault")
+	// ErrStalePointer means a pointer read from the game was null or pointed
+	// into the never-mapped low 64K, usually because the structure it came
+	// from was freed or hasn't been set up yet
+	ErrStalePointer = errors.New("stale pointer")
+)
+
+// minValidAddress is the lowest address Windows maps in a user process
+const minValidAddress = 0x10000
+
+// CheckPointer returns an ErrStalePointer error if address can't point to process memory.
+func CheckPointer(address uintptr) error {
+	if address < minValidAddress {
+		return fmt.Errorf("%w: 0x%X", ErrStalePointer, address)
+	}
+	return nil
+}
+
+// readFault wraps an error returned by a ProcessReader as an ErrReadFault.
+func readFault(address uintptr, err error) error {
+	return fmt.Errorf("%w at 0x%X: %v", ErrReadFault, address, err)
+}
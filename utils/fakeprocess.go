@@ -0,0 +1,117 @@
+// utils/fakeprocess.go
+
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// FakeBase is where FakeProcess maps its memory
+	FakeBase = uintptr(0x140000000)
+	// FakeModuleSize is the size of the module image at the start of FakeProcess memory
+	FakeModuleSize = 0x2000
+)
+
+// FakeProcess is a ProcessReader over a flat buffer mapped at FakeBase, with a
+// minimal PE header so that ModuleImageSize and ModuleTimestamp work. Tests
+// build game structures in it to run the readers without a live process.
+type FakeProcess struct {
+	Memory []byte
+	Reads  int // ReadRaw calls, which every other read goes through
+}
+
+// NewFakeProcess maps size bytes, the first FakeModuleSize of them being the module image.
+func NewFakeProcess(size int) *FakeProcess {
+	fp := &FakeProcess{Memory: make([]byte, size)}
+	copy(fp.Memory, "MZ")
+	binary.LittleEndian.PutUint32(fp.Memory[0x3C:], 0x80)
+	copy(fp.Memory[0x80:], "PE\x00\x00")
+	binary.LittleEndian.PutUint32(fp.Memory[0x88:], 0x5F3759DF)               // TimeDateStamp
+	binary.LittleEndian.PutUint32(fp.Memory[0x80+4+20+0x38:], FakeModuleSize) // SizeOfImage
+	return fp
+}
+
+// Put writes data at address.
+func (fp *FakeProcess) Put(address uintptr, data []byte) {
+	copy(fp.Memory[address-FakeBase:], data)
+}
+
+// PutUint16 writes a little-endian value at address.
+func (fp *FakeProcess) PutUint16(address uintptr, value uint16) {
+	binary.LittleEndian.PutUint16(fp.Memory[address-FakeBase:], value)
+}
+
+// PutUint32 writes a little-endian value at address.
+func (fp *FakeProcess) PutUint32(address uintptr, value uint32) {
+	binary.LittleEndian.PutUint32(fp.Memory[address-FakeBase:], value)
+}
+
+// PutUint64 writes a little-endian value, such as a pointer, at address.
+func (fp *FakeProcess) PutUint64(address uintptr, value uint64) {
+	binary.LittleEndian.PutUint64(fp.Memory[address-FakeBase:], value)
+}
+
+func (fp *FakeProcess) BaseAddress() uintptr {
+	return FakeBase
+}
+
+func (fp *FakeProcess) Read(address uintptr, dataType string, offsets ...uintptr) (interface{}, error) {
+	size, ok := aTypeSize[dataType]
+	if !ok {
+		return nil, fmt.Errorf("invalid data type")
+	}
+	buf, err := fp.ReadRaw(address, uint32(size), offsets...)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBuffer(buf, 0, dataType)
+}
+
+func (fp *FakeProcess) ReadRaw(address uintptr, size uint32, offsets ...uintptr) ([]byte, error) {
+	fp.Reads++
+	for _, offset := range offsets {
+		pointer, err := fp.slice(address, 8)
+		if err != nil {
+			return nil, err
+		}
+		address = uintptr(binary.LittleEndian.Uint64(pointer)) + offset
+	}
+	data, err := fp.slice(address, size)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (fp *FakeProcess) ReadString(address uintptr, sizeBytes int, encoding string, offsets ...uintptr) (string, error) {
+	buf, err := fp.ReadRaw(address, uint32(sizeBytes), offsets...)
+	if err != nil {
+		return "", err
+	}
+	return decodeString(buf, encoding)
+}
+
+// ModulePatternScan returns the address of the first match of pattern in the module image.
+func (fp *FakeProcess) ModulePatternScan(moduleName string, pattern string) (uintptr, error) {
+	needle, err := ParsePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+	offset := PatternScan(fp.Memory[:min(len(fp.Memory), FakeModuleSize)], needle)
+	if offset == -1 {
+		return 0, errors.New("pattern not found")
+	}
+	return FakeBase + uintptr(offset), nil
+}
+
+// slice returns the mapped bytes at address, failing like an unmapped page outside the buffer.
+func (fp *FakeProcess) slice(address uintptr, size uint32) ([]byte, error) {
+	if address < FakeBase || address-FakeBase+uintptr(size) > uintptr(len(fp.Memory)) {
+		return nil, fmt.Errorf("address 0x%X is not mapped", address)
+	}
+	start := address - FakeBase
+	return fp.Memory[start : start+uintptr(size)], nil
+}
@@ -0,0 +1,249 @@
+// utils/memory_linux.go
+
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcMemory is the Linux ProcessReader for D2R running under Wine/Proton.
+// It locates the module through /proc/<pid>/maps and reads through /proc/<pid>/mem,
+// which needs the same ptrace access as a debugger (same user and
+// kernel.yama.ptrace_scope <= 1, or CAP_SYS_PTRACE).
+type ProcMemory struct {
+	baseAddress    uintptr
+	PID            uint32
+	CurrentProgram string
+	mem            *os.File
+	startTime      uint64 // of the process at attach, tells it apart from a later process reusing the PID
+}
+
+// NewProcMemory finds the Wine process running program (e.g. "D2R.exe") and opens its memory.
+func NewProcMemory(program string) (*ProcMemory, error) {
+	pid, err := findWinePID(program)
+	if err != nil {
+		return nil, err
+	}
+	return NewProcMemoryForPID(pid, program)
+}
+
+// NewProcMemoryForPID opens the memory of pid and locates the mapping of the program image.
+func NewProcMemoryForPID(pid uint32, program string) (*ProcMemory, error) {
+	baseAddr, err := findModuleMapping(pid, program)
+	if err != nil {
+		return nil, err
+	}
+	startTime, _, err := processStat(pid)
+	if err != nil {
+		return nil, err
+	}
+	mem, err := os.Open(fmt.Sprintf("/proc/%d/mem", pid))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open process memory: %w", err)
+	}
+	return &ProcMemory{
+		baseAddress:    baseAddr,
+		PID:            pid,
+		CurrentProgram: program,
+		mem:            mem,
+		startTime:      startTime,
+	}, nil
+}
+
+// BaseAddress returns the address the program image is mapped at.
+func (pm *ProcMemory) BaseAddress() uintptr {
+	return pm.baseAddress
+}
+
+func (pm *ProcMemory) Close() error {
+	return pm.mem.Close()
+}
+
+// ProcessID returns the PID of the Wine process.
+func (pm *ProcMemory) ProcessID() uint32 {
+	return pm.PID
+}
+
+// AttachProcess opens the Wine process running program.
+func AttachProcess(program string) (Process, error) {
+	pm, err := NewProcMemory(program)
+	if err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// IsHandleValid reports whether the target process is still running. A zombie
+// or another process that was given the same PID doesn't count.
+func (pm *ProcMemory) IsHandleValid() bool {
+	startTime, state, err := processStat(pm.PID)
+	return err == nil && startTime == pm.startTime && state != 'Z' && state != 'X'
+}
+
+func (pm *ProcMemory) Read(address uintptr, dataType string, offsets ...uintptr) (interface{}, error) {
+	size, ok := aTypeSize[dataType]
+	if !ok {
+		return nil, fmt.Errorf("invalid data type")
+	}
+	buf, err := pm.ReadRaw(address, uint32(size), offsets...)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBuffer(buf, 0, dataType)
+}
+
+func (pm *ProcMemory) ReadRaw(address uintptr, size uint32, offsets ...uintptr) ([]byte, error) {
+	finalAddress, err := pm.calculateFinalAddress(address, offsets...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if _, err := pm.mem.ReadAt(buf, int64(finalAddress)); err != nil {
+		return nil, fmt.Errorf("failed to read process memory at address 0x%X: %w", finalAddress, err)
+	}
+	return buf, nil
+}
+
+func (pm *ProcMemory) ReadString(address uintptr, sizeBytes int, encoding string, offsets ...uintptr) (string, error) {
+	if sizeBytes == 0 {
+		sizeBytes = 256
+	}
+	buf, err := pm.ReadRaw(address, uint32(sizeBytes), offsets...)
+	if err != nil {
+		return "", err
+	}
+	return decodeString(buf, encoding)
+}
+
+func (pm *ProcMemory) ModulePatternScan(moduleName string, pattern string) (uintptr, error) {
+	moduleSize, err := ModuleImageSize(pm)
+	if err != nil {
+		return 0, err
+	}
+	needle, err := ParsePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+	buffer, err := pm.ReadRaw(pm.baseAddress, moduleSize)
+	if err != nil {
+		return 0, err
+	}
+	offset := PatternScan(buffer, needle)
+	if offset == -1 {
+		return 0, errors.New("pattern not found")
+	}
+	return pm.baseAddress + uintptr(offset), nil
+}
+
+// calculateFinalAddress follows a chain of 64-bit pointers, D2R being a 64-bit process.
+func (pm *ProcMemory) calculateFinalAddress(address uintptr, offsets ...uintptr) (uintptr, error) {
+	finalAddress := address
+	var buf [8]byte
+	for _, offset := range offsets {
+		if _, err := pm.mem.ReadAt(buf[:], int64(finalAddress)); err != nil {
+			return 0, err
+		}
+		finalAddress = uintptr(binary.LittleEndian.Uint64(buf[:])) + offset
+	}
+	return finalAddress, nil
+}
+
+// findWinePID returns the first process whose command name or argv[0] is program.
+// Wine sets the command name to the Windows executable name, while argv[0]
+// holds its Windows or Unix path.
+func findWinePID(program string) (uint32, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		if err == nil && strings.EqualFold(strings.TrimSpace(string(comm)), program) {
+			return uint32(pid), nil
+		}
+		cmdline, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		argv0 := strings.SplitN(string(cmdline), "\x00", 2)[0]
+		if strings.EqualFold(windowsBaseName(argv0), program) {
+			return uint32(pid), nil
+		}
+	}
+	return 0, errors.New("process not found")
+}
+
+// processStat returns the start time, in clock ticks since boot, and the state of pid from /proc/<pid>/stat.
+func processStat(pid uint32) (uint64, byte, error) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	// pid (comm) state ppid ... with starttime the 22nd field; comm may hold spaces and parentheses
+	end := strings.LastIndexByte(string(stat), ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	fields := strings.Fields(string(stat[end+1:]))
+	if len(fields) < 20 {
+		return 0, 0, fmt.Errorf("malformed /proc/%d/stat", pid)
+	}
+	startTime, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed /proc/%d/stat: %w", pid, err)
+	}
+	return startTime, fields[0][0], nil
+}
+
+// findModuleMapping returns the start of the lowest mapping of the program image in /proc/<pid>/maps.
+func findModuleMapping(pid uint32, program string) (uintptr, error) {
+	file, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var base uintptr
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// start-end perms offset dev inode pathname
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		pathname := strings.Join(fields[5:], " ")
+		if !strings.EqualFold(windowsBaseName(pathname), program) {
+			continue
+		}
+		start, err := strconv.ParseUint(strings.SplitN(fields[0], "-", 2)[0], 16, 64)
+		if err != nil {
+			continue
+		}
+		if base == 0 || uintptr(start) < base {
+			base = uintptr(start)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	if base == 0 {
+		return 0, errors.New("module not found")
+	}
+	return base, nil
+}
+
+// windowsBaseName returns the last element of a path using either separator.
+func windowsBaseName(path string) string {
+	return path[strings.LastIndexAny(path, `\/`)+1:]
+}
@@ -0,0 +1,149 @@
+// utils/memory_linux_test.go
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+const (
+	standInEnv    = "GALYMAP_STAND_IN" // holds the path of the module image the stand-in maps
+	standInBuffer = "GalyMap stand-in buffer\x00"
+	// standInPattern is written into the module image at standInPatternOffset
+	standInPattern       = "\x48\x8D\x0D\x5A\xA5\x3C\xC3"
+	standInPatternOffset = 0x1800
+)
+
+// TestStandInProcess is not a test: it is the child process of TestProcMemory,
+// which maps the module image, prints the addresses of the image and of a
+// known buffer, and keeps them alive until stdin closes.
+func TestStandInProcess(t *testing.T) {
+	imagePath := os.Getenv(standInEnv)
+	if imagePath == "" {
+		t.Skip("only runs as the stand-in child of TestProcMemory")
+	}
+	file, err := os.Open(imagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image, err := syscall.Mmap(int(file.Fd()), 0, FakeModuleSize, syscall.PROT_READ, syscall.MAP_PRIVATE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	buffer := []byte(standInBuffer)
+	value := new(uint32)
+	*value = 0xC0FFEE
+	pointer := new(uintptr)
+	*pointer = uintptr(unsafe.Pointer(value)) - 4
+
+	fmt.Printf("%x %x %x\n", uintptr(unsafe.Pointer(&image[0])), uintptr(unsafe.Pointer(&buffer[0])), uintptr(unsafe.Pointer(pointer)))
+	io.Copy(io.Discard, os.Stdin)
+	runtime.KeepAlive(buffer)
+	runtime.KeepAlive(value)
+	runtime.KeepAlive(pointer)
+	syscall.Munmap(image)
+	os.Exit(0)
+}
+
+func TestProcMemory(t *testing.T) {
+	// The module image is the PE header of the fake process with a pattern to scan for.
+	// The stand-in runs under a Windows path as argv[0], the way Wine starts D2R.
+	program := fmt.Sprintf("GalyMapStandIn%d.exe", os.Getpid())
+	image := NewFakeProcess(FakeModuleSize)
+	image.Put(FakeBase+standInPatternOffset, []byte(standInPattern))
+	imagePath := filepath.Join(t.TempDir(), program)
+	if err := os.WriteFile(imagePath, image.Memory, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	child := exec.Command(os.Args[0], "-test.run=^TestStandInProcess$")
+	child.Args[0] = `C:\Program Files (x86)\Diablo II Resurrected\` + program
+	child.Env = append(os.Environ(), standInEnv+"="+imagePath)
+	stdin, err := child.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := child.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Start(); err != nil {
+		t.Fatalf("failed to start stand-in process: %v", err)
+	}
+	defer child.Process.Kill()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("stand-in process didn't print its addresses: %v", err)
+	}
+	fields := strings.Fields(line)
+	imageAddress, _ := strconv.ParseUint(fields[0], 16, 64)
+	bufferAddress, _ := strconv.ParseUint(fields[1], 16, 64)
+	pointerAddress, _ := strconv.ParseUint(fields[2], 16, 64)
+
+	pm, err := NewProcMemory(program)
+	if err != nil {
+		t.Fatalf("NewProcMemory: %v", err)
+	}
+	defer pm.Close()
+
+	if pm.PID != uint32(child.Process.Pid) {
+		t.Errorf("NewProcMemory attached to PID %d, want the stand-in %d", pm.PID, child.Process.Pid)
+	}
+	if pm.BaseAddress() != uintptr(imageAddress) {
+		t.Errorf("BaseAddress = 0x%X, want the mapped image at 0x%X", pm.BaseAddress(), imageAddress)
+	}
+	if size, err := ModuleImageSize(pm); err != nil || size != FakeModuleSize {
+		t.Errorf("ModuleImageSize = 0x%X, %v; want 0x%X", size, err, FakeModuleSize)
+	}
+	if address, err := pm.ModulePatternScan(program, "48 8D 0D ?? A5 3C C3"); err != nil || address != uintptr(imageAddress)+standInPatternOffset {
+		t.Errorf("ModulePatternScan = 0x%X, %v; want 0x%X", address, err, uintptr(imageAddress)+standInPatternOffset)
+	}
+
+	if text, err := pm.ReadString(uintptr(bufferAddress), len(standInBuffer), "utf-8"); err != nil || text != strings.TrimRight(standInBuffer, "\x00") {
+		t.Errorf("ReadString = %q, %v; want %q", text, err, standInBuffer)
+	}
+	if value, err := pm.Read(uintptr(pointerAddress), "UInt", 4); err != nil || value != uint32(0xC0FFEE) {
+		t.Errorf("Read through a pointer = %v, %v; want 0xC0FFEE", value, err)
+	}
+	if !pm.IsHandleValid() {
+		t.Errorf("IsHandleValid is false for a running process")
+	}
+
+	reused := *pm
+	reused.startTime++
+	if reused.IsHandleValid() {
+		t.Errorf("IsHandleValid is true for another process with the same PID")
+	}
+
+	// Until it is waited for, the exited child is a zombie that still has its /proc entry
+	stdin.Close()
+	for {
+		_, state, err := processStat(pm.PID)
+		if err != nil || state == 'Z' {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pm.IsHandleValid() {
+		t.Errorf("IsHandleValid is true for an exited process")
+	}
+	child.Wait()
+	if pm.IsHandleValid() {
+		t.Errorf("IsHandleValid is true for a reaped process")
+	}
+}
@@ -0,0 +1,204 @@
+// utils/patternscan.go
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Pattern is a parsed byte signature. Mask selects the bits of each byte that
+// have to match: "48" has mask 0xFF, "4?" has mask 0xF0 and "??" has mask 0x00.
+type Pattern struct {
+	Value []byte
+	Mask  []byte
+}
+
+// ParsePattern converts a hex signature such as "48 8B 05 ?? ?? ?? ??" or "4? 8B" into a Pattern.
+func ParsePattern(hexString string) (Pattern, error) {
+	hexString = strings.ReplaceAll(hexString, " ", "")
+	hexString = strings.ReplaceAll(hexString, "\t", "")
+	hexString = strings.ReplaceAll(hexString, "0x", "")
+	if len(hexString)%2 != 0 {
+		return Pattern{}, errors.New("hex string has invalid length")
+	}
+	if len(hexString) == 0 {
+		return Pattern{}, errors.New("empty pattern")
+	}
+
+	pattern := Pattern{
+		Value: make([]byte, len(hexString)/2),
+		Mask:  make([]byte, len(hexString)/2),
+	}
+	for i := 0; i < len(hexString); i++ {
+		shift := 4 * uint(1-i%2) // high nibble first
+		c := hexString[i]
+		if c == '?' {
+			continue
+		}
+		nibble, ok := hexNibble(c)
+		if !ok {
+			return Pattern{}, fmt.Errorf("invalid hex digit %q in pattern", c)
+		}
+		pattern.Value[i/2] |= nibble << shift
+		pattern.Mask[i/2] |= 0xF << shift
+	}
+	return pattern, nil
+}
+
+func hexNibble(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Len returns the length of the pattern in bytes.
+func (p Pattern) Len() int {
+	return len(p.Value)
+}
+
+// Bytes returns the pattern as plain bytes, failing if it contains wildcards.
+func (p Pattern) Bytes() ([]byte, error) {
+	for _, m := range p.Mask {
+		if m != 0xFF {
+			return nil, errors.New("pattern contains wildcards")
+		}
+	}
+	return p.Value, nil
+}
+
+// matchAt reports whether the pattern matches data starting at offset i.
+func (p Pattern) matchAt(data []byte, i int) bool {
+	if i < 0 || i+len(p.Value) > len(data) {
+		return false
+	}
+	for j, v := range p.Value {
+		if data[i+j]&p.Mask[j] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// anchor returns the index of the fully known byte used to find match candidates,
+// preferring bytes that are uncommon in x64 code. It returns -1 if no byte is fully known.
+func (p Pattern) anchor() int {
+	best := -1
+	for j, m := range p.Mask {
+		if m != 0xFF {
+			continue
+		}
+		if best == -1 {
+			best = j
+		}
+		switch p.Value[j] {
+		case 0x00, 0xFF, 0xCC, 0x48, 0x89, 0x8B, 0x0F, 0x24:
+			continue
+		}
+		return j
+	}
+	return best
+}
+
+// PatternScan returns the offset of the first match of pattern in data, or -1.
+// With a single pattern, the candidates are found by jumping from one anchor
+// byte to the next with bytes.IndexByte rather than looking at every byte.
+func PatternScan(data []byte, pattern Pattern) int {
+	a := pattern.anchor()
+	if a == -1 {
+		matches := NewScanner(pattern).scan(data, true)
+		if len(matches[0]) == 0 {
+			return -1
+		}
+		return matches[0][0]
+	}
+	anchorByte := pattern.Value[a]
+	for i := a; i < len(data); i++ {
+		next := bytes.IndexByte(data[i:], anchorByte)
+		if next == -1 {
+			return -1
+		}
+		i += next
+		if pattern.matchAt(data, i-a) {
+			return i - a
+		}
+	}
+	return -1
+}
+
+// Scanner finds every match of several patterns in a single pass over a buffer.
+// Each pattern is indexed by one fully known anchor byte, so only positions
+// holding an anchor byte are compared against the patterns.
+type Scanner struct {
+	patterns   []Pattern
+	anchors    [256][]scanAnchor
+	unanchored []int
+}
+
+type scanAnchor struct {
+	pattern int
+	offset  int
+}
+
+// NewScanner prepares a scanner for the given patterns.
+func NewScanner(patterns ...Pattern) *Scanner {
+	s := &Scanner{patterns: patterns}
+	for i, p := range patterns {
+		a := p.anchor()
+		if a == -1 {
+			s.unanchored = append(s.unanchored, i)
+			continue
+		}
+		b := p.Value[a]
+		s.anchors[b] = append(s.anchors[b], scanAnchor{pattern: i, offset: a})
+	}
+	return s
+}
+
+// ScanAll returns, for each pattern in the order given to NewScanner, the offsets of all its matches in data.
+func (s *Scanner) ScanAll(data []byte) [][]int {
+	return s.scan(data, false)
+}
+
+func (s *Scanner) scan(data []byte, firstOnly bool) [][]int {
+	matches := make([][]int, len(s.patterns))
+	found := 0
+
+	for i, b := range data {
+		for _, a := range s.anchors[b] {
+			if firstOnly && matches[a.pattern] != nil {
+				continue
+			}
+			start := i - a.offset
+			if s.patterns[a.pattern].matchAt(data, start) {
+				if matches[a.pattern] == nil {
+					found++
+				}
+				matches[a.pattern] = append(matches[a.pattern], start)
+			}
+		}
+		for _, p := range s.unanchored {
+			if firstOnly && matches[p] != nil {
+				continue
+			}
+			if s.patterns[p].matchAt(data, i) {
+				if matches[p] == nil {
+					found++
+				}
+				matches[p] = append(matches[p], i)
+			}
+		}
+		if firstOnly && found == len(s.patterns) {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,187 @@
+// utils/patternscan_test.go
+
+package utils
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    Pattern
+		wantErr bool
+	}{
+		{"48 8B ??", Pattern{Value: []byte{0x48, 0x8B, 0x00}, Mask: []byte{0xFF, 0xFF, 0x00}}, false},
+		{"4? ?B", Pattern{Value: []byte{0x40, 0x0B}, Mask: []byte{0xF0, 0x0F}}, false},
+		{"3F", Pattern{Value: []byte{0x3F}, Mask: []byte{0xFF}}, false},
+		{"0x3F 0x48", Pattern{Value: []byte{0x3F, 0x48}, Mask: []byte{0xFF, 0xFF}}, false},
+		{"48 8", Pattern{}, true},
+		{"", Pattern{}, true},
+		{"4G", Pattern{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePattern(tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePattern(%q) error = %v, want error %v", tt.pattern, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePattern(%q) = %+v, want %+v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestScanner(t *testing.T) {
+	data := []byte{0x90, 0x41, 0x8B, 0x3F, 0x00, 0x4F, 0x8B, 0x3F, 0x3F, 0xCC, 0x48, 0x8B, 0x05}
+	tests := []struct {
+		name    string
+		pattern string
+		want    []int
+	}{
+		{"high nibble mask", "4? 8B", []int{1, 5, 10}},
+		{"low nibble mask", "?F 8B", []int{5}},
+		{"literal 0x3F is no wildcard", "3F 3F", []int{7}},
+		{"literal 0x3F after a wildcard", "?? 3F", []int{2, 6, 7}},
+		{"match at the end of the buffer", "48 8B 05", []int{10}},
+		{"match at the start of the buffer", "90 4?", []int{0}},
+		{"pattern past the end", "8B 05 00", nil},
+		{"only wildcards", "?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ??", []int{0, 1}},
+		{"no match", "3F CC 48 00", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := ParsePattern(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := NewScanner(pattern).ScanAll(data)[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanAll = %v, want %v", got, tt.want)
+			}
+			first := -1
+			if len(tt.want) > 0 {
+				first = tt.want[0]
+			}
+			if got := PatternScan(data, pattern); got != first {
+				t.Errorf("PatternScan = %d, want %d", got, first)
+			}
+		})
+	}
+}
+
+func TestScannerMatchesNaiveScan(t *testing.T) {
+	needle := []byte{0x48, 0x8D, 0x0D, 0x11, 0x22, 0x33, 0x44, 0xE8}
+	data := syntheticModule(1<<20, needle)
+	for _, text := range []string{"48 8D 0D ?? ?? ?? ?? E8", "4? 8D", "8B ?? 24", "?? ?? 3F", "00 00 00 00"} {
+		pattern, err := ParsePattern(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := naiveScan(data, pattern, false)
+		if got := NewScanner(pattern).ScanAll(data)[0]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: ScanAll found %d matches, the naive scan %d", text, len(got), len(want))
+		}
+		first := -1
+		if len(want) > 0 {
+			first = want[0]
+		}
+		if got := PatternScan(data, pattern); got != first {
+			t.Errorf("%s: PatternScan = %d, the naive scan %d", text, got, first)
+		}
+	}
+}
+
+// syntheticModule returns size bytes of pseudo-random code-like data with needle
+// written near the end, so that scans have to cover the whole buffer.
+func syntheticModule(size int, needle []byte) []byte {
+	data := make([]byte, size)
+	rng := rand.New(rand.NewSource(1))
+	common := []byte{0x00, 0x48, 0x89, 0x8B, 0x0F, 0xCC, 0xFF, 0x24}
+	for i := range data {
+		if rng.Intn(2) == 0 {
+			data[i] = common[rng.Intn(len(common))]
+		} else {
+			data[i] = byte(rng.Intn(256))
+		}
+	}
+	copy(data[size-len(needle)-16:], needle)
+	return data
+}
+
+// naiveScan is the byte-by-byte masked comparison the scanner replaced, kept
+// as the baseline of BenchmarkPatternScan. It returns the offsets of every
+// match, or only the first one when first is set.
+func naiveScan(data []byte, pattern Pattern, first bool) []int {
+	var matches []int
+	for i := 0; i+len(pattern.Value) <= len(data); i++ {
+		match := true
+		for j := range pattern.Value {
+			if data[i+j]&pattern.Mask[j] != pattern.Value[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			matches = append(matches, i)
+			if first {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func BenchmarkPatternScan(b *testing.B) {
+	const size = 32 << 20 // about the size of the D2R.exe image
+	needle := []byte{0x48, 0x8D, 0x0D, 0x11, 0x22, 0x33, 0x44, 0xE8, 0x55, 0x66, 0x77, 0x88, 0x48, 0x8B, 0x05}
+	data := syntheticModule(size, needle)
+	patterns := []string{
+		"48 8D 0D ?? ?? ?? ?? E8 ?? ?? ?? ?? 48 8B 05",
+		"4? 8D 0D ?? ?? ?? ?? E8",
+		"40 84 ED 0F 94 05",
+		"48 03 C7 49 8B 8C C6",
+		"?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? 3F",
+	}
+	parsed := make([]Pattern, len(patterns))
+	for i, text := range patterns {
+		var err error
+		if parsed[i], err = ParsePattern(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	// The naive scan runs once per pattern, as every signature used to be scanned for
+	b.Run("naive first", func(b *testing.B) {
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			if matches := naiveScan(data, parsed[0], true); len(matches) == 0 || matches[0] != size-len(needle)-16 {
+				b.Fatal("needle not found")
+			}
+		}
+	})
+	b.Run("naive all", func(b *testing.B) {
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			for _, pattern := range parsed {
+				naiveScan(data, pattern, false)
+			}
+		}
+	})
+	b.Run("first", func(b *testing.B) {
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			if PatternScan(data, parsed[0]) != size-len(needle)-16 {
+				b.Fatal("needle not found")
+			}
+		}
+	})
+	b.Run("all", func(b *testing.B) {
+		scanner := NewScanner(parsed...)
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			scanner.ScanAll(data)
+		}
+	})
+}
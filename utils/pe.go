@@ -0,0 +1,57 @@
+// utils/pe.go
+
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// peHeaderAddress returns the address of the "PE\0\0" signature of the module loaded at BaseAddress.
+func peHeaderAddress(d2r ProcessReader) (uintptr, error) {
+	dosHeader, err := d2r.ReadRaw(d2r.BaseAddress(), 0x40)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read DOS header: %w", err)
+	}
+	if len(dosHeader) < 0x40 || dosHeader[0] != 'M' || dosHeader[1] != 'Z' {
+		return 0, fmt.Errorf("no DOS header at 0x%X", d2r.BaseAddress())
+	}
+	peAddress := d2r.BaseAddress() + uintptr(binary.LittleEndian.Uint32(dosHeader[0x3C:0x40]))
+	signature, err := d2r.ReadRaw(peAddress, 4)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read PE signature: %w", err)
+	}
+	if len(signature) < 4 || string(signature) != "PE\x00\x00" {
+		return 0, fmt.Errorf("no PE signature at 0x%X", peAddress)
+	}
+	return peAddress, nil
+}
+
+// ModuleImageSize returns SizeOfImage from the PE optional header of the main module.
+func ModuleImageSize(d2r ProcessReader) (uint32, error) {
+	peAddress, err := peHeaderAddress(d2r)
+	if err != nil {
+		return 0, err
+	}
+	// Signature (4) + COFF file header (20) + SizeOfImage offset in the optional header (0x38)
+	size, err := ReadAndAssert[uint32](d2r, peAddress+4+20+0x38, "UInt")
+	if err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// ModuleTimestamp returns TimeDateStamp from the PE file header of the main module.
+// It changes with every build of the executable.
+func ModuleTimestamp(d2r ProcessReader) (uint32, error) {
+	peAddress, err := peHeaderAddress(d2r)
+	if err != nil {
+		return 0, err
+	}
+	// Signature (4) + Machine (2) + NumberOfSections (2)
+	timestamp, err := ReadAndAssert[uint32](d2r, peAddress+8, "UInt")
+	if err != nil {
+		return 0, err
+	}
+	return timestamp, nil
+}
@@ -0,0 +1,15 @@
+// utils/process.go
+
+package utils
+
+// Process is a ProcessReader attached to a running process. ClassMemory
+// implements it on Windows and ProcMemory under Wine/Proton on Linux.
+type Process interface {
+	ProcessReader
+	// ProcessID returns the PID of the attached process.
+	ProcessID() uint32
+	// IsHandleValid reports whether the process is still running.
+	IsHandleValid() bool
+	// Close releases the handle to the process.
+	Close() error
+}
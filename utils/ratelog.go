@@ -0,0 +1,63 @@
+// utils/ratelog.go
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// RateLimitedLog logs a message at most once per interval for each key.
+// Messages suppressed in between are counted and the count is reported
+// with the next message that gets through.
+type RateLimitedLog struct {
+	interval time.Duration
+	now      func() time.Time // the clock, replaced in tests
+	mu       sync.Mutex
+	entries  map[string]*rateLimitedEntry
+}
+
+type rateLimitedEntry struct {
+	last       time.Time
+	suppressed int
+}
+
+// NewRateLimitedLog returns a log that lets one message per key through every interval.
+func NewRateLimitedLog(interval time.Duration) *RateLimitedLog {
+	return &RateLimitedLog{
+		interval: interval,
+		now:      time.Now,
+		entries:  make(map[string]*rateLimitedEntry),
+	}
+}
+
+// Printf logs the formatted message unless a message with the same key was logged within the interval.
+func (rl *RateLimitedLog) Printf(key string, format string, args ...interface{}) {
+	rl.mu.Lock()
+	entry, ok := rl.entries[key]
+	if !ok {
+		entry = &rateLimitedEntry{}
+		rl.entries[key] = entry
+	}
+	now := rl.now()
+	if ok && now.Sub(entry.last) < rl.interval {
+		entry.suppressed++
+		rl.mu.Unlock()
+		return
+	}
+	suppressed := entry.suppressed
+	entry.last = now
+	entry.suppressed = 0
+	rl.mu.Unlock()
+
+	msg := fmt.Sprintf(format, args...)
+	if suppressed > 0 {
+		msg += fmt.Sprintf(" (%d similar messages suppressed)", suppressed)
+	}
+	log.Print(msg)
+}
+
+// errorLog deduplicates IfError messages by call site
+var errorLog = NewRateLimitedLog(10 * time.Second)
@@ -0,0 +1,53 @@
+// utils/ratelog_test.go
+
+package utils
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedLog(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	flags := log.Flags()
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	})
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	rl := NewRateLimitedLog(10 * time.Second)
+	rl.now = func() time.Time { return now }
+
+	steps := []struct {
+		after time.Duration // since start
+		key   string
+		want  string // the logged line, empty if suppressed
+	}{
+		{0, "a", "a 1"},
+		{time.Second, "a", ""},
+		{time.Second, "b", "b 3"},
+		{9 * time.Second, "a", ""},
+		{10 * time.Second, "a", "a 5 (2 similar messages suppressed)"},
+		{11 * time.Second, "b", "b 6"},
+		{15 * time.Second, "a", ""},
+		{25 * time.Second, "a", "a 8 (1 similar messages suppressed)"},
+		{26 * time.Second, "a", ""},
+		{40 * time.Second, "a", "a 10 (1 similar messages suppressed)"},
+	}
+	for i, step := range steps {
+		now = start.Add(step.after)
+		out.Reset()
+		rl.Printf(step.key, "%s %d", step.key, i+1)
+		if got := strings.TrimSuffix(out.String(), "\n"); got != step.want {
+			t.Errorf("step %d at %v: logged %q, want %q", i+1, step.after, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// utils/readcache.go
+
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	cachePageSize = 0x1000
+	// maxPlanGapPages is the number of uncached pages a planned read may span
+	// to join two ranges into a single read.
+	maxPlanGapPages = 1
+)
+
+// ReadStats counts the work done by a CachedReader.
+type ReadStats struct {
+	Requests    uint64        // reads asked of the CachedReader
+	Hits        uint64        // requests served entirely from cached pages
+	SourceReads uint64        // reads issued to the underlying process
+	SourceBytes uint64        // bytes read from the underlying process
+	SourceTime  time.Duration // time spent in reads of the underlying process
+}
+
+// CachedReader is a ProcessReader that serves reads from a short-lived page
+// cache. Pages are read from the source on first use, or ahead of time with a
+// ReadPlan, and kept until Reset, which the tick loop calls once per tick.
+// Pages that fail to read, such as unmapped ones, are remembered until Reset too,
+// so that every read touching them fails without asking the source again.
+// It is not safe for concurrent use.
+type CachedReader struct {
+	source ProcessReader
+	pages  map[uintptr][]byte
+	failed map[uintptr]error // pages that couldn't be read, with the error of the read
+	stats  ReadStats
+}
+
+// NewCachedReader returns a caching reader on top of source.
+func NewCachedReader(source ProcessReader) *CachedReader {
+	return &CachedReader{
+		source: source,
+		pages:  make(map[uintptr][]byte),
+		failed: make(map[uintptr]error),
+	}
+}
+
+// Source returns the reader the cache reads from.
+func (cr *CachedReader) Source() ProcessReader {
+	return cr.source
+}
+
+// Reset drops every cached and failed page so the next reads see fresh memory.
+func (cr *CachedReader) Reset() {
+	clear(cr.pages)
+	clear(cr.failed)
+}
+
+// TakeStats returns the counters accumulated since the last call and zeroes them.
+func (cr *CachedReader) TakeStats() ReadStats {
+	stats := cr.stats
+	cr.stats = ReadStats{}
+	return stats
+}
+
+func (cr *CachedReader) BaseAddress() uintptr {
+	return cr.source.BaseAddress()
+}
+
+func (cr *CachedReader) Read(address uintptr, dataType string, offsets ...uintptr) (interface{}, error) {
+	size, ok := aTypeSize[dataType]
+	if !ok {
+		return nil, fmt.Errorf("invalid data type")
+	}
+	buf, err := cr.ReadRaw(address, uint32(size), offsets...)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBuffer(buf, 0, dataType)
+}
+
+func (cr *CachedReader) ReadRaw(address uintptr, size uint32, offsets ...uintptr) ([]byte, error) {
+	finalAddress, err := cr.calculateFinalAddress(address, offsets...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if err := cr.copyOut(finalAddress, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (cr *CachedReader) ReadString(address uintptr, sizeBytes int, encoding string, offsets ...uintptr) (string, error) {
+	if sizeBytes == 0 {
+		sizeBytes = 256
+	}
+	finalAddress, err := cr.calculateFinalAddress(address, offsets...)
+	if err != nil {
+		return "", err
+	}
+	// Strings are usually much shorter than sizeBytes, so don't fault in
+	// pages past the end of the page holding the terminator.
+	buf := make([]byte, sizeBytes)
+	if err := cr.copyOutString(finalAddress, buf, encoding); err != nil {
+		return "", err
+	}
+	return decodeString(buf, encoding)
+}
+
+func (cr *CachedReader) ModulePatternScan(moduleName string, pattern string) (uintptr, error) {
+	return cr.source.ModulePatternScan(moduleName, pattern)
+}
+
+// calculateFinalAddress follows a chain of 64-bit pointers, D2R being a 64-bit process.
+func (cr *CachedReader) calculateFinalAddress(address uintptr, offsets ...uintptr) (uintptr, error) {
+	finalAddress := address
+	var buf [8]byte
+	for _, offset := range offsets {
+		if err := cr.copyOut(finalAddress, buf[:]); err != nil {
+			return 0, err
+		}
+		finalAddress = uintptr(binary.LittleEndian.Uint64(buf[:])) + offset
+	}
+	return finalAddress, nil
+}
+
+// copyOut fills buf from cached pages starting at address, reading missing pages from the source.
+func (cr *CachedReader) copyOut(address uintptr, buf []byte) error {
+	cr.stats.Requests++
+	hit := true
+	for done := 0; done < len(buf); {
+		current := address + uintptr(done)
+		pageAddress := current &^ (cachePageSize - 1)
+		page, cached, err := cr.page(pageAddress)
+		if err != nil {
+			return fmt.Errorf("failed to read memory at address 0x%X: %w", current, err)
+		}
+		hit = hit && cached
+		done += copy(buf[done:], page[current-pageAddress:])
+	}
+	if hit {
+		cr.stats.Hits++
+	}
+	return nil
+}
+
+// copyOutString is copyOut for strings: it stops at the page holding the null terminator.
+func (cr *CachedReader) copyOutString(address uintptr, buf []byte, encoding string) error {
+	cr.stats.Requests++
+	hit := true
+	for done := 0; done < len(buf); {
+		current := address + uintptr(done)
+		pageAddress := current &^ (cachePageSize - 1)
+		page, cached, err := cr.page(pageAddress)
+		if err != nil {
+			return fmt.Errorf("failed to read memory at address 0x%X: %w", current, err)
+		}
+		hit = hit && cached
+		done += copy(buf[done:], page[current-pageAddress:])
+		if hasTerminator(buf[:done], encoding) {
+			break
+		}
+	}
+	if hit {
+		cr.stats.Hits++
+	}
+	return nil
+}
+
+// hasTerminator reports whether buf holds a null terminator in the given encoding.
+func hasTerminator(buf []byte, encoding string) bool {
+	if strings.ToLower(encoding) == "utf-16" {
+		for i := 0; i+1 < len(buf); i += 2 {
+			if buf[i] == 0 && buf[i+1] == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	for _, b := range buf {
+		if b == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// page returns a cached page, reading it from the source if needed.
+func (cr *CachedReader) page(pageAddress uintptr) ([]byte, bool, error) {
+	if page, ok := cr.pages[pageAddress]; ok {
+		return page, true, nil
+	}
+	if err, ok := cr.failed[pageAddress]; ok {
+		return nil, true, err
+	}
+	if err := cr.fetch(pageAddress, 1); err != nil {
+		return nil, false, err
+	}
+	return cr.pages[pageAddress], false, nil
+}
+
+// fetch reads count pages starting at pageAddress with a single source read and caches them.
+// A single page that fails is cached as failed.
+func (cr *CachedReader) fetch(pageAddress uintptr, count int) error {
+	size := uint32(count * cachePageSize)
+	start := time.Now()
+	data, err := cr.source.ReadRaw(pageAddress, size)
+	cr.stats.SourceTime += time.Since(start)
+	cr.stats.SourceReads++
+	if err == nil && len(data) != int(size) {
+		err = errors.New("short page read")
+	}
+	if err != nil {
+		if count == 1 {
+			cr.failed[pageAddress] = err
+		}
+		return err
+	}
+	cr.stats.SourceBytes += uint64(size)
+	for i := 0; i < count; i++ {
+		cr.pages[pageAddress+uintptr(i*cachePageSize)] = data[i*cachePageSize : (i+1)*cachePageSize]
+	}
+	return nil
+}
+
+// load reads every page covered by ranges that isn't cached yet. Ranges that are
+// close together are merged so that each run of pages is read once.
+func (cr *CachedReader) load(ranges []ReadRange) error {
+	var missing []uintptr
+	seen := make(map[uintptr]bool)
+	for _, r := range ranges {
+		if r.Size == 0 {
+			continue
+		}
+		first := r.Address &^ (cachePageSize - 1)
+		last := (r.Address + uintptr(r.Size) - 1) &^ (cachePageSize - 1)
+		for p := first; p <= last; p += cachePageSize {
+			if _, ok := cr.pages[p]; ok {
+				continue
+			}
+			if _, ok := cr.failed[p]; !ok && !seen[p] {
+				seen[p] = true
+				missing = append(missing, p)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+
+	var firstErr error
+	runStart, runEnd := missing[0], missing[0]
+	flush := func() {
+		count := int((runEnd-runStart)/cachePageSize) + 1
+		if err := cr.fetch(runStart, count); err != nil && count > 1 {
+			// One page of the run may be unmapped; fall back to page-sized reads.
+			for p := runStart; p <= runEnd; p += cachePageSize {
+				if seen[p] {
+					if err := cr.fetch(p, 1); err != nil && firstErr == nil {
+						firstErr = err
+					}
+				}
+			}
+		} else if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, p := range missing[1:] {
+		if p-runEnd <= (maxPlanGapPages+1)*cachePageSize {
+			runEnd = p
+			continue
+		}
+		flush()
+		runStart, runEnd = p, p
+	}
+	flush()
+	return firstErr
+}
+
+// ReadRange is an address range to read.
+type ReadRange struct {
+	Address uintptr
+	Size    uint32
+}
+
+// ReadPlan collects the address ranges a reading phase needs so they can be
+// loaded together. On readers other than CachedReader it does nothing, and the
+// reads that follow go to the process one by one as before.
+type ReadPlan struct {
+	reader *CachedReader
+	ranges []ReadRange
+}
+
+// NewReadPlan starts an empty plan for d2r.
+func NewReadPlan(d2r ProcessReader) *ReadPlan {
+	reader, _ := d2r.(*CachedReader)
+	return &ReadPlan{reader: reader}
+}
+
+// Enabled reports whether the plan batches reads, i.e. whether it was made for a CachedReader.
+func (rp *ReadPlan) Enabled() bool {
+	return rp.reader != nil
+}
+
+// Add queues size bytes at address. Null addresses are ignored.
+func (rp *ReadPlan) Add(address uintptr, size uint32) {
+	if rp.reader == nil || address == 0 {
+		return
+	}
+	rp.ranges = append(rp.ranges, ReadRange{Address: address, Size: size})
+}
+
+// Execute loads every queued range into the cache and empties the plan.
+// Ranges that can't be read are left for the individual reads to report.
+func (rp *ReadPlan) Execute() error {
+	if rp.reader == nil || len(rp.ranges) == 0 {
+		return nil
+	}
+	err := rp.reader.load(rp.ranges)
+	rp.ranges = rp.ranges[:0]
+	return err
+}
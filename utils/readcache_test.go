@@ -0,0 +1,66 @@
+// utils/readcache_test.go
+
+package utils
+
+import "testing"
+
+func TestReadPlanSourceReads(t *testing.T) {
+	process := NewFakeProcess(FakeModuleSize + 8*cachePageSize)
+	page := func(i int) uintptr { return FakeBase + FakeModuleSize + uintptr(i)*cachePageSize }
+	reader := NewCachedReader(process)
+
+	// Pages 0-2 are one run. Page 5, 7 and the unmapped page 8 are a second run,
+	// which fails as a whole and is read again page by page.
+	plan := NewReadPlan(reader)
+	plan.Add(page(0)+0x10, 0x1800)
+	plan.Add(page(2)+0x20, 8)
+	plan.Add(page(5), 8)
+	plan.Add(page(7)+0xFF0, 0x20)
+	if err := plan.Execute(); err == nil {
+		t.Errorf("Execute didn't report the unmapped page")
+	}
+	if process.Reads != 5 {
+		t.Errorf("Execute made %d source reads, want 5", process.Reads)
+	}
+
+	// The reads of the tick are served from the cache
+	for _, r := range []ReadRange{{page(0) + 0x10, 0x1800}, {page(2) + 0x20, 8}, {page(5), 8}, {page(7) + 0xFF0, 0x10}} {
+		if _, err := reader.ReadRaw(r.Address, r.Size); err != nil {
+			t.Errorf("ReadRaw(0x%X, %d): %v", r.Address, r.Size, err)
+		}
+	}
+	// and so are the failures of the unmapped page, as often as it is read
+	for i := 0; i < 3; i++ {
+		if _, err := reader.ReadRaw(page(7)+0xFF0, 0x20); err == nil {
+			t.Errorf("reading into the unmapped page succeeded")
+		}
+		if _, err := reader.Read(page(8), "UInt"); err == nil {
+			t.Errorf("reading the unmapped page succeeded")
+		}
+	}
+	if process.Reads != 5 {
+		t.Errorf("planned reads made %d source reads in all, want 5", process.Reads)
+	}
+
+	// A page that wasn't planned is read once
+	reader.Read(page(3), "UInt")
+	reader.Read(page(3)+8, "UInt")
+	if process.Reads != 6 {
+		t.Errorf("unplanned reads made %d source reads in all, want 6", process.Reads)
+	}
+	if stats := reader.TakeStats(); stats.SourceReads != uint64(process.Reads) {
+		t.Errorf("stats count %d source reads, want %d", stats.SourceReads, process.Reads)
+	}
+
+	// The next tick asks the source again, also for the page that failed
+	reader.Reset()
+	if _, err := reader.Read(page(8), "UInt"); err == nil {
+		t.Errorf("reading the unmapped page succeeded")
+	}
+	if _, err := reader.Read(page(0), "UInt"); err != nil {
+		t.Errorf("Read after Reset: %v", err)
+	}
+	if process.Reads != 8 {
+		t.Errorf("reads after Reset made %d source reads in all, want 8", process.Reads)
+	}
+}
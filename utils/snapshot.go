@@ -0,0 +1,221 @@
+// utils/snapshot.go
+
+package utils
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	snapshotVersion  = 1
+	snapshotPageSize = 0x1000
+)
+
+// Snapshot is a point-in-time copy of the D2R module image plus every page
+// that was read while it was being recorded.
+type Snapshot struct {
+	Version     int
+	CapturedAt  time.Time
+	Tick        int64
+	BaseAddress uintptr
+	Module      []byte
+	Pages       map[uintptr][]byte // page-aligned address -> snapshotPageSize bytes
+}
+
+// SnapshotReader is a ProcessReader that serves reads from a Snapshot.
+// When created with NewRecordingReader it faults missing pages in from a
+// live process, so the snapshot ends up holding exactly what was read.
+type SnapshotReader struct {
+	snap   *Snapshot
+	source ProcessReader
+}
+
+// NewSnapshotReader returns a reader that replays a captured snapshot.
+func NewSnapshotReader(snap *Snapshot) *SnapshotReader {
+	return &SnapshotReader{snap: snap}
+}
+
+// NewRecordingReader copies the module image of source and returns a reader
+// that records every page read through it.
+func NewRecordingReader(source ProcessReader) (*SnapshotReader, error) {
+	moduleSize, err := ModuleImageSize(source)
+	if err != nil {
+		return nil, err
+	}
+	module, err := source.ReadRaw(source.BaseAddress(), moduleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module image: %w", err)
+	}
+	snap := &Snapshot{
+		Version:     snapshotVersion,
+		CapturedAt:  time.Now(),
+		BaseAddress: source.BaseAddress(),
+		Module:      module,
+		Pages:       make(map[uintptr][]byte),
+	}
+	return &SnapshotReader{snap: snap, source: source}, nil
+}
+
+// OpenSnapshot loads a snapshot file and returns a reader for it.
+func OpenSnapshot(filePath string) (*SnapshotReader, error) {
+	snap, err := LoadSnapshot(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewSnapshotReader(snap), nil
+}
+
+// Snapshot returns the underlying snapshot.
+func (sr *SnapshotReader) Snapshot() *Snapshot {
+	return sr.snap
+}
+
+func (sr *SnapshotReader) BaseAddress() uintptr {
+	return sr.snap.BaseAddress
+}
+
+func (sr *SnapshotReader) Read(address uintptr, dataType string, offsets ...uintptr) (interface{}, error) {
+	size, ok := aTypeSize[dataType]
+	if !ok {
+		return nil, fmt.Errorf("invalid data type")
+	}
+	buf, err := sr.ReadRaw(address, uint32(size), offsets...)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBuffer(buf, 0, dataType)
+}
+
+func (sr *SnapshotReader) ReadRaw(address uintptr, size uint32, offsets ...uintptr) ([]byte, error) {
+	finalAddress, err := sr.calculateFinalAddress(address, offsets...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	if err := sr.copyOut(finalAddress, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (sr *SnapshotReader) ReadString(address uintptr, sizeBytes int, encoding string, offsets ...uintptr) (string, error) {
+	if sizeBytes == 0 {
+		sizeBytes = 256
+	}
+	buf, err := sr.ReadRaw(address, uint32(sizeBytes), offsets...)
+	if err != nil {
+		return "", err
+	}
+	return decodeString(buf, encoding)
+}
+
+func (sr *SnapshotReader) ModulePatternScan(moduleName string, pattern string) (uintptr, error) {
+	needle, err := ParsePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+	offset := PatternScan(sr.snap.Module, needle)
+	if offset == -1 {
+		return 0, errors.New("pattern not found")
+	}
+	return sr.snap.BaseAddress + uintptr(offset), nil
+}
+
+// calculateFinalAddress follows a chain of 64-bit pointers, D2R being a 64-bit process.
+func (sr *SnapshotReader) calculateFinalAddress(address uintptr, offsets ...uintptr) (uintptr, error) {
+	finalAddress := address
+	var buf [8]byte
+	for _, offset := range offsets {
+		if err := sr.copyOut(finalAddress, buf[:]); err != nil {
+			return 0, err
+		}
+		finalAddress = uintptr(binary.LittleEndian.Uint64(buf[:])) + offset
+	}
+	return finalAddress, nil
+}
+
+// copyOut fills buf from the module image and recorded pages starting at address.
+func (sr *SnapshotReader) copyOut(address uintptr, buf []byte) error {
+	moduleEnd := sr.snap.BaseAddress + uintptr(len(sr.snap.Module))
+	for done := 0; done < len(buf); {
+		current := address + uintptr(done)
+		if current >= sr.snap.BaseAddress && current < moduleEnd {
+			done += copy(buf[done:], sr.snap.Module[current-sr.snap.BaseAddress:])
+			continue
+		}
+		pageAddress := current &^ (snapshotPageSize - 1)
+		page, err := sr.page(pageAddress)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot memory at address 0x%X: %w", current, err)
+		}
+		done += copy(buf[done:], page[current-pageAddress:])
+	}
+	return nil
+}
+
+// page returns a recorded page, faulting it in from the live process when recording.
+func (sr *SnapshotReader) page(pageAddress uintptr) ([]byte, error) {
+	if page, ok := sr.snap.Pages[pageAddress]; ok {
+		return page, nil
+	}
+	if sr.source == nil {
+		return nil, errors.New("page not captured")
+	}
+	page, err := sr.source.ReadRaw(pageAddress, snapshotPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(page) != snapshotPageSize {
+		return nil, errors.New("short page read")
+	}
+	sr.snap.Pages[pageAddress] = page
+	return page, nil
+}
+
+// SaveSnapshot writes a snapshot as gzip-compressed gob.
+func SaveSnapshot(filePath string, snap *Snapshot) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	if err := gob.NewEncoder(zw).Encode(snap); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot.
+func LoadSnapshot(filePath string) (*Snapshot, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var snap Snapshot
+	if err := gob.NewDecoder(zr).Decode(&snap); err != nil {
+		return nil, err
+	}
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+	return &snap, nil
+}
@@ -0,0 +1,76 @@
+// utils/snapshot_test.go
+
+package utils
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	process := NewFakeProcess(FakeModuleSize + 4*snapshotPageSize)
+	heap := FakeBase + FakeModuleSize + 0x10
+	player := FakeBase + FakeModuleSize + 2*snapshotPageSize + 0x20
+	process.PutUint64(FakeBase+0x1000, uint64(heap)) // module global -> heap
+	process.PutUint64(heap, uint64(player))          // heap -> player
+	process.Put(player+0x8, []byte{0x39, 0x05, 0, 0})
+	process.Put(player+0x100, []byte("Player\x00"))
+
+	// read walks the same reads against any reader, as a memory tick would
+	read := func(reader ProcessReader) (uint32, string, error) {
+		value, err := reader.Read(FakeBase+0x1000, "UInt", 0, 0x8)
+		if err != nil {
+			return 0, "", err
+		}
+		name, err := reader.ReadString(FakeBase+0x1000, 16, "utf-8", 0, 0x100)
+		return value.(uint32), name, err
+	}
+
+	recorder, err := NewRecordingReader(process)
+	if err != nil {
+		t.Fatalf("NewRecordingReader: %v", err)
+	}
+	wantValue, wantName, err := read(recorder)
+	if err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+	if wantValue != 1337 || wantName != "Player" {
+		t.Fatalf("recorded %d %q, want 1337 \"Player\"", wantValue, wantName)
+	}
+
+	filePath := filepath.Join(t.TempDir(), "tick.snap")
+	if err := SaveSnapshot(filePath, recorder.Snapshot()); err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	replay, err := OpenSnapshot(filePath)
+	if err != nil {
+		t.Fatalf("OpenSnapshot: %v", err)
+	}
+
+	snap := replay.Snapshot()
+	if snap.BaseAddress != FakeBase || !bytes.Equal(snap.Module, process.Memory[:FakeModuleSize]) {
+		t.Errorf("module image was not saved as read")
+	}
+	if len(snap.Pages) != 2 {
+		t.Errorf("snapshot holds %d pages, want the 2 that were read", len(snap.Pages))
+	}
+
+	// Change the live process, the replay must not see it
+	process.Put(player+0x8, []byte{0, 0, 0, 0})
+	value, name, err := read(replay)
+	if err != nil {
+		t.Fatalf("replaying: %v", err)
+	}
+	if value != wantValue || name != wantName {
+		t.Errorf("replayed %d %q, want %d %q", value, name, wantValue, wantName)
+	}
+	if size, err := ModuleImageSize(replay); err != nil || size != FakeModuleSize {
+		t.Errorf("ModuleImageSize = 0x%X, %v; want 0x%X", size, err, FakeModuleSize)
+	}
+
+	// A page that wasn't read while recording is missing from the replay
+	if _, err := replay.ReadRaw(FakeBase+FakeModuleSize+3*snapshotPageSize, 4); err == nil {
+		t.Errorf("reading a page that wasn't recorded succeeded")
+	}
+}
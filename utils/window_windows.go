@@ -0,0 +1,49 @@
+// utils/window_windows.go
+
+package utils
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// SetWindowPos sets the position and size of a window
+func SetWindowPos(hwnd, targetHwnd windows.HWND, x, y, width, height int) error {
+	modUser32 := windows.NewLazySystemDLL("user32.dll")
+	procSetWindowPos := modUser32.NewProc("SetWindowPos")
+
+	ret, _, err := procSetWindowPos.Call(
+		uintptr(hwnd),
+		uintptr(targetHwnd),
+		uintptr(x),
+		uintptr(y),
+		uintptr(width),
+		uintptr(height),
+		uintptr(0x0001|0x0002|0x0010), // SWP_NOACTIVATE | SWP_NOSIZE | SWP_NOZORDER
+	)
+	if ret == 0 {
+		return fmt.Errorf("SetWindowPos failed: %v", err)
+	}
+	return nil
+}
+
+// FindWindow retrieves the HWND of a window based on its title
+func FindWindow(title string) (windows.HWND, error) {
+	modUser32 := windows.NewLazySystemDLL("user32.dll")
+	procFindWindow := modUser32.NewProc("FindWindowW")
+
+	titleUTF16, err := syscall.UTF16PtrFromString(title)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode window title: %v", err)
+	}
+
+	hwnd, _, _ := procFindWindow.Call(0, uintptr(unsafe.Pointer(titleUTF16)))
+	if hwnd == 0 {
+		return 0, fmt.Errorf("window not found: %s", title)
+	}
+
+	return windows.HWND(hwnd), nil
+}